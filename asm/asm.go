@@ -0,0 +1,162 @@
+// Package asm assembles programs for the CPU added by alu.WithCPU into the words of its RAM, and disassembles them.
+//
+// Each line contains an optional label followed by a colon, and an optional statement,
+// with comments starting with "#" or ";" and running to the end of the line, such as:
+//
+//	start:	LDA x	; load the word at x
+//		ADD y
+//		OUT
+//		HLT
+//	x:	.word 28
+//	y:	.word 14
+//
+// Statements are the mnemonics in alu.Instructions, followed by an operand for the ones that use it,
+// ".word" followed by a value, and ".org" followed by the address of the next word.
+// Mnemonics and directives are case-insensitive, while labels are not.
+// Operands and values are labels or numbers in Go syntax, such as 5, 0x5 or 0b101.
+package asm
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kssilveira/circuit-engine/lib/alu"
+)
+
+var label = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// statement is a line with a statement.
+type statement struct {
+	line    int
+	address int
+	fields  []string
+}
+
+// Assemble returns the words of the program for a CPU with n address bits, starting at address 0.
+func Assemble(src string, n int) ([]uint64, error) {
+	labels := map[string]int{}
+	var statements []statement
+	address := 0
+	for i, line := range strings.Split(src, "\n") {
+		if index := strings.IndexAny(line, "#;"); index >= 0 {
+			line = line[:index]
+		}
+		if name, rest, ok := strings.Cut(line, ":"); ok {
+			name = strings.TrimSpace(name)
+			if !label.MatchString(name) {
+				return nil, fmt.Errorf("line %d: invalid label %q", i+1, name)
+			}
+			if _, ok := labels[name]; ok {
+				return nil, fmt.Errorf("line %d: label %q defined twice", i+1, name)
+			}
+			labels[name] = address
+			line = rest
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if strings.EqualFold(fields[0], ".org") {
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("line %d: want .org and an address", i+1)
+			}
+			org, err := strconv.ParseUint(fields[1], 0, 64)
+			if err != nil || int(org) < address || org > 1<<n {
+				return nil, fmt.Errorf("line %d: invalid .org %q, want an address from %d to %d", i+1, fields[1], address, 1<<n)
+			}
+			address = int(org)
+			continue
+		}
+		statements = append(statements, statement{line: i + 1, address: address, fields: fields})
+		address++
+	}
+	if address > 1<<n {
+		return nil, fmt.Errorf("program has %d words, want at most %d", address, 1<<n)
+	}
+	res := make([]uint64, address)
+	for _, one := range statements {
+		word, err := encode(one.fields, labels, n)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", one.line, err)
+		}
+		res[one.address] = word
+	}
+	return res, nil
+}
+
+// encode returns the word of a statement.
+func encode(fields []string, labels map[string]int, n int) (uint64, error) {
+	if strings.EqualFold(fields[0], ".word") {
+		if len(fields) != 2 {
+			return 0, fmt.Errorf("want .word and a value")
+		}
+		return value(fields[1], labels, alu.OpcodeBits+n)
+	}
+	for _, one := range alu.Instructions {
+		if !strings.EqualFold(one.Mnemonic, fields[0]) {
+			continue
+		}
+		word := uint64(one.Opcode) << n
+		if !one.Operand {
+			if len(fields) != 1 {
+				return 0, fmt.Errorf("%s takes no operand, got %q", one.Mnemonic, fields[1:])
+			}
+			return word, nil
+		}
+		if len(fields) != 2 {
+			return 0, fmt.Errorf("%s takes one operand, got %q", one.Mnemonic, fields[1:])
+		}
+		operand, err := value(fields[1], labels, n)
+		return word | operand, err
+	}
+	var names []string
+	for _, one := range alu.Instructions {
+		names = append(names, one.Mnemonic)
+	}
+	return 0, fmt.Errorf("invalid mnemonic %q, valid ones are %q", fields[0], names)
+}
+
+// value returns the value of a label or number, which must fit in the given bits.
+func value(field string, labels map[string]int, bits int) (uint64, error) {
+	res, err := strconv.ParseUint(field, 0, 64)
+	if err != nil {
+		address, ok := labels[field]
+		if !ok {
+			return 0, fmt.Errorf("invalid value %q, want a number or label", field)
+		}
+		res = uint64(address)
+	}
+	if res>>bits != 0 {
+		return 0, fmt.Errorf("value %q is %#x, want at most %d bits", field, res, bits)
+	}
+	return res, nil
+}
+
+// Disassemble returns the statement of a word for a CPU with n address bits, which assembles back into it:
+// the instruction with its operand, or .word with the value of words that are not instructions.
+func Disassemble(word uint64, n int) string {
+	opcode, operand := word>>n, word&(1<<n-1)
+	for _, one := range alu.Instructions {
+		switch {
+		case uint64(one.Opcode) != opcode:
+		case one.Operand:
+			return fmt.Sprintf("%s %d", one.Mnemonic, operand)
+		case operand == 0:
+			return one.Mnemonic
+		}
+	}
+	return fmt.Sprintf(".word %#x", word)
+}
+
+// Listing returns the statements of the words for a CPU with n address bits, one per line,
+// followed by a comment with their address and value, with as many hex digits as the widest word.
+func Listing(words []uint64, n int) string {
+	digits := (alu.OpcodeBits + n + 3) / 4
+	var res strings.Builder
+	for i, word := range words {
+		fmt.Fprintf(&res, "\t%s\t; %d: 0x%0*x\n", Disassemble(word, n), i, digits, word)
+	}
+	return res.String()
+}
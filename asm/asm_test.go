@@ -0,0 +1,112 @@
+package asm
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAssemble(t *testing.T) {
+	src := `
+start:	LDI 5	; comment
+	add x
+	JMP end # another comment
+	.ORG 6
+end:	OUT
+	HLT
+x:	.word 0x1c
+	.Word start
+`
+	got, err := Assemble(src, 4)
+	if err != nil {
+		t.Fatalf("Assemble got err %v", err)
+	}
+	want := []uint64{0x55, 0x28, 0x66, 0, 0, 0, 0xe0, 0xf0, 28, 0}
+	if !slices.Equal(got, want) {
+		t.Errorf("Assemble want %#x got %#x", want, got)
+	}
+}
+
+func TestAssembleErrors(t *testing.T) {
+	for _, in := range []struct {
+		src  string
+		want string
+	}{{
+		src:  "FOO 1",
+		want: `line 1: invalid mnemonic "FOO"`,
+	}, {
+		src:  "OUT 1",
+		want: "line 1: OUT takes no operand",
+	}, {
+		src:  "\nLDA",
+		want: "line 2: LDA takes one operand",
+	}, {
+		src:  "LDA x",
+		want: `line 1: invalid value "x"`,
+	}, {
+		src:  "LDI 16",
+		want: "want at most 4 bits",
+	}, {
+		src:  ".word 256",
+		want: "want at most 8 bits",
+	}, {
+		src:  "x: OUT\nx: HLT",
+		want: `line 2: label "x" defined twice`,
+	}, {
+		src:  "1x: OUT",
+		want: `line 1: invalid label "1x"`,
+	}, {
+		src:  "OUT\nOUT\n.org 1",
+		want: "line 3: invalid .org",
+	}, {
+		src:  strings.Repeat("OUT\n", 17),
+		want: "program has 17 words, want at most 16",
+	}} {
+		_, err := Assemble(in.src, 4)
+		if err == nil || !strings.Contains(err.Error(), in.want) {
+			t.Errorf("Assemble(%q) want err containing %q got %v", in.src, in.want, err)
+		}
+	}
+}
+
+func TestDisassemble(t *testing.T) {
+	for _, in := range []struct {
+		word uint64
+		want string
+	}{
+		{0x55, "LDI 5"},
+		{0xe0, "OUT"},
+		{0xe1, ".word 0xe1"},
+		{0x70, ".word 0x70"},
+	} {
+		if got := Disassemble(in.word, 4); got != in.want {
+			t.Errorf("Disassemble(%#x) want %q got %q", in.word, in.want, got)
+		}
+	}
+	// every word assembles back, 16 at a time to fit in the RAM
+	for start := uint64(0); start < 256; start += 16 {
+		var words []uint64
+		for word := start; word < start+16; word++ {
+			words = append(words, word)
+		}
+		got, err := Assemble(Listing(words, 4), 4)
+		if err != nil || !slices.Equal(got, words) {
+			t.Errorf("Assemble(Listing(%#x)) got %#x and err %v", words, got, err)
+		}
+	}
+}
+
+func TestListing(t *testing.T) {
+	for _, in := range []struct {
+		words []uint64
+		n     int
+		want  string
+	}{
+		{[]uint64{0x55, 0x01}, 4, "\tLDI 5\t; 0: 0x55\n\t.word 0x1\t; 1: 0x01\n"},
+		{[]uint64{0x1c0, 0x01}, 5, "\tOUT\t; 0: 0x1c0\n\t.word 0x1\t; 1: 0x001\n"},
+	} {
+		if got := Listing(in.words, in.n); got != in.want {
+			t.Errorf("Listing(%#x, %d) want %q got %q", in.words, in.n, in.want, got)
+		}
+	}
+}
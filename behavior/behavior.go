@@ -0,0 +1,102 @@
+// Package behavior encapsulates blocks simulated by Go functions instead of transistors.
+//
+// A Block reads its inputs with bit.Bit.Get, which stores it as a reader, so setting an input
+// updates the block, and it sets its outputs with bit.Bit.Set, which updates their readers.
+// Add blocks with group.Group.Behavior, such as:
+//
+//	g := c.Group("ROM")
+//	g.Behavior(address, data, func(ins, _ []bool) []bool {
+//		return table[index(ins)]
+//	})
+package behavior
+
+import (
+	"strings"
+
+	"github.com/kssilveira/circuit-engine/config"
+	"github.com/kssilveira/circuit-engine/draw"
+	"github.com/kssilveira/circuit-engine/graphid"
+	"github.com/kssilveira/circuit-engine/sfmt"
+	"github.com/kssilveira/circuit-engine/wire"
+)
+
+// Block computes its outputs from its inputs with a Go function.
+type Block struct {
+	Name string
+	Ins  []*wire.Wire
+	Outs []*wire.Wire
+	// Eval returns the output values given the input values and the current output values,
+	// which hold the state of sequential blocks.
+	//
+	// It is called on every update, even if the inputs did not change, so it should not have side effects,
+	// and outputs set without updating, such as by reg.Preset, are read by the next update.
+	Eval     func(ins, outs []bool) []bool
+	updating int
+}
+
+// Update calls Eval, and updates the outputs and the readers of the ones that changed.
+//
+// It panics if Eval returns the wrong number of outputs.
+func (b *Block) Update(updateReaders bool) {
+	if b.updating >= 10 {
+		return
+	}
+	ins := make([]bool, len(b.Ins))
+	for i, w := range b.Ins {
+		ins[i] = w.Bit.Get(b)
+	}
+	b.updating++
+	outs := make([]bool, len(b.Outs))
+	for i, w := range b.Outs {
+		outs[i] = w.Bit.SilentGet()
+	}
+	res := b.Eval(ins, outs)
+	if len(res) != len(outs) {
+		panic(sfmt.Sprintf("block %q returned %d outputs, want %d", b.Name, len(res), len(outs)))
+	}
+	for i, v := range res {
+		b.Outs[i].Bit.Set(v, b, updateReaders)
+	}
+	b.updating--
+}
+
+func (b Block) String(depth int, _ config.Config) string {
+	var res []string
+	for _, wire := range append(append([]*wire.Wire{}, b.Ins...), b.Outs...) {
+		one := sfmt.Sprintf("%v", *wire)
+		if one == "" {
+			continue
+		}
+		res = append(res, one)
+	}
+	return sfmt.Sprintf("%s%s %s", draw.StringPrefix(depth), b.Name, strings.Join(res, "    "))
+}
+
+// Graph returns the graphviz graph.
+func (b *Block) Graph(depth int, cfg config.Config, ids *graphid.IDs) string {
+	if !cfg.DrawNodes {
+		return ""
+	}
+	prefix := draw.GraphPrefix(depth)
+	id, _ := ids.ID("b", b)
+	res := []string{sfmt.Sprintf(`%s"%s" [label="%s";shape=box];`, prefix, id, b.Name)}
+	for _, wire := range b.Ins {
+		wireID, node := draw.WireNode(ids, wire, cfg.DrawShapePoint)
+		if node != "" {
+			res = append(res, prefix+node)
+		}
+		if cfg.DrawEdges {
+			res = append(res, sfmt.Sprintf(`%s"%s" -> "%s" %s;`, prefix, wireID, id, draw.EdgeColor(wire, wire)))
+		}
+	}
+	for _, wire := range b.Outs {
+		wireID, node := draw.WireNode(ids, wire, cfg.DrawShapePoint)
+		if node != "" {
+			res = append(res, prefix+node)
+		}
+		if cfg.DrawEdges {
+			res = append(res, sfmt.Sprintf(`%s"%s" -> "%s" %s;`, prefix, id, wireID, draw.EdgeColor(wire, wire)))
+		}
+	}
+	return strings.Join(res, "\n")
+}
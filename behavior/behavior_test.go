@@ -0,0 +1,68 @@
+package behavior
+
+import (
+	"testing"
+
+	"github.com/kssilveira/circuit-engine/wire"
+)
+
+func TestUpdate(t *testing.T) {
+	in, out := &wire.Wire{Name: "in"}, &wire.Wire{Name: "out"}
+	calls := 0
+	b := &Block{Name: "NOT", Ins: []*wire.Wire{in}, Outs: []*wire.Wire{out}, Eval: func(ins, _ []bool) []bool {
+		calls++
+		return []bool{!ins[0]}
+	}}
+	b.Update(true)
+	b.Update(true)
+	if !out.Bit.Get(nil) || calls != 2 {
+		t.Errorf("Update twice want out=1 and 2 calls got out=%t and %d calls", out.Bit.Get(nil), calls)
+	}
+	// the block reads its input, so setting it updates the block
+	in.Bit.Set(true, nil, true)
+	if out.Bit.Get(nil) || calls != 3 {
+		t.Errorf("Set(in) want out=0 and 3 calls got out=%t and %d calls", out.Bit.Get(nil), calls)
+	}
+}
+
+func TestUpdatePreset(t *testing.T) {
+	en, q, res := &wire.Wire{Name: "en"}, &wire.Wire{Name: "q"}, &wire.Wire{Name: "res"}
+	en.Bit.SilentSet(true)
+	// a register that keeps q and outputs it when enabled
+	b := &Block{Name: "R", Ins: []*wire.Wire{en}, Outs: []*wire.Wire{q, res}, Eval: func(ins, outs []bool) []bool {
+		return []bool{outs[0], outs[0] && ins[0]}
+	}}
+	b.Update(true)
+	q.Bit.SilentSet(true)
+	b.Update(true)
+	if !res.Bit.Get(nil) {
+		t.Errorf("Update after preset with the same inputs want res=1 got 0")
+	}
+}
+
+func TestUpdateNoInputs(t *testing.T) {
+	out := &wire.Wire{Name: "out"}
+	calls := 0
+	b := &Block{Name: "CONST", Outs: []*wire.Wire{out}, Eval: func(_, _ []bool) []bool {
+		calls++
+		return []bool{true}
+	}}
+	b.Update(true)
+	out.Bit.SilentSet(false)
+	b.Update(true)
+	if !out.Bit.Get(nil) || calls != 2 {
+		t.Errorf("Update twice want out=1 and 2 calls got out=%t and %d calls", out.Bit.Get(nil), calls)
+	}
+}
+
+func TestUpdateWrongOutputs(t *testing.T) {
+	b := &Block{Name: "BAD", Outs: []*wire.Wire{{Name: "out"}}, Eval: func(_, _ []bool) []bool {
+		return nil
+	}}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Update with no outputs want panic")
+		}
+	}()
+	b.Update(true)
+}
@@ -57,6 +57,11 @@ func (b *Bit) Set(v bool, writer component.Component, updateReaders bool) {
 	b.writer = writer
 }
 
+// Readers returns the components that read the bit.
+func (b *Bit) Readers() []component.Component {
+	return b.readers
+}
+
 // SilentSet sets the bit without updating the readers.
 func (b *Bit) SilentSet(v bool) {
 	b.bit = v
@@ -2,11 +2,15 @@
 package circuit
 
 import (
+	"fmt"
 	"math/rand/v2"
 	"strings"
+	"time"
 
 	"github.com/kssilveira/circuit-engine/component"
 	"github.com/kssilveira/circuit-engine/config"
+	"github.com/kssilveira/circuit-engine/draw/svg"
+	"github.com/kssilveira/circuit-engine/graphid"
 	"github.com/kssilveira/circuit-engine/group"
 	"github.com/kssilveira/circuit-engine/sfmt"
 	"github.com/kssilveira/circuit-engine/wire"
@@ -33,16 +37,16 @@ func (c *Circuit) In(name string) *wire.Wire {
 	return res
 }
 
-// Group adds a group.
+// Group adds a group, see group.New.
 func (c *Circuit) Group(name string) *group.Group {
-	res := &group.Group{Name: name}
+	res := group.New(name, c.Config)
 	c.Components = append(c.Components, res)
 	return res
 }
 
 // Out adds an output.
 func (c *Circuit) Out(res *wire.Wire) {
-	c.Outputs = append(c.Outputs, res)
+	c.Outs([]*wire.Wire{res})
 }
 
 // Outs adds multiple outputs.
@@ -79,19 +83,49 @@ func (c *Circuit) Description() string {
 	return strings.Join(res, " ")
 }
 
+// Find returns the group at the given path, see group.Find.
+func (c Circuit) Find(path string) (*group.Group, error) {
+	root := &group.Group{Components: c.Components}
+	return root.Find(path)
+}
+
+// view returns the inputs, outputs and components to print and draw.
+//
+// With Config.Focus, they are the ports of the group at that path and the group, or an error if there is no such group.
+func (c Circuit) view() ([]*wire.Wire, []*wire.Wire, []component.Component, error) {
+	if c.Config.Focus == "" {
+		return c.Inputs, c.Outputs, c.Components, nil
+	}
+	g, err := c.Find(c.Config.Focus)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid focus: %w", err)
+	}
+	inputs, outputs := g.Ports(c.Outputs)
+	return inputs, outputs, []component.Component{g}, nil
+}
+
+// String returns the circuit, or the error of an invalid Config.Focus instead of an empty circuit.
 func (c Circuit) String() string {
+	inputs, outputs, components, err := c.view()
+	if err != nil {
+		return err.Error()
+	}
 	var res []string
 	var list []string
-	for _, input := range c.Inputs {
+	for _, input := range inputs {
 		list = append(list, sfmt.Sprintf("  %v", *input))
 	}
 	res = append(res, sfmt.Sprintf("Inputs: %s", strings.Join(list, "")))
 	res = append(res, sfmt.Sprintf("Outputs:"))
-	for _, output := range c.Outputs {
+	for _, output := range outputs {
 		res = append(res, sfmt.Sprintf("  %v", *output))
 	}
 	res = append(res, "Components: ")
-	for _, component := range c.Components {
+	for _, component := range components {
+		if g, ok := component.(*group.Group); ok {
+			res = append(res, g.StringOutputs(0, c.Config, outputs))
+			continue
+		}
 		res = append(res, component.String(0, c.Config))
 	}
 	return strings.Join(res, "\n")
@@ -110,84 +144,214 @@ func (c Circuit) StringForUnitTest() string {
 	return strings.Join(res, "")
 }
 
-// Graph returns the graphviz graph.
+// Graph returns the graphviz graph, or the error of an invalid Config.Focus, which graphviz rejects.
 func (c Circuit) Graph() string {
+	inputs, outputs, components, err := c.view()
+	if err != nil {
+		return err.Error()
+	}
 	res := []string{
 		"digraph {",
 		" rankdir=LR;",
 	}
-	for _, input := range c.Inputs {
-		res = append(res, sfmt.Sprintf(` "%v"[shape=rarrow;fillcolor=black;style=filled;fontcolor=white;fontsize=30];`, *input))
-	}
-	for _, output := range c.Outputs {
-		res = append(res, sfmt.Sprintf(` "%v"[shape=rarrow;fillcolor=black;style=filled;fontcolor=white;fontsize=30];`, *output))
+	ids := graphid.New()
+	for _, wire := range append(append([]*wire.Wire{}, inputs...), outputs...) {
+		id, ok := ids.Wire(wire.Name, wire)
+		if !ok {
+			continue
+		}
+		res = append(res, sfmt.Sprintf(` "%s"[label="%v";shape=rarrow;fillcolor=black;style=filled;fontcolor=white;fontsize=30];`, id, *wire))
 	}
-	for _, component := range c.Components {
-		res = append(res, component.Graph(1, c.Config))
+	for i, component := range components {
+		ids.Enter(i)
+		if g, ok := component.(*group.Group); ok {
+			res = append(res, g.GraphOutputs(1, c.Config, ids, outputs))
+		} else {
+			res = append(res, component.Graph(1, c.Config, ids))
+		}
+		ids.Leave()
 	}
 	res = append(res, "}")
 	return strings.Join(res, "\n")
 }
 
+// SVG returns the SVG drawing, or the error of an invalid Config.Focus.
+func (c Circuit) SVG() string {
+	inputs, outputs, components, err := c.view()
+	if err != nil {
+		return err.Error()
+	}
+	return svg.Render(inputs, outputs, components, c.Config)
+}
+
+// Animate simulates the circuit for the given inputs and returns an HTML page with one frame per valid input.
+//
+// The circuit is laid out after the first update, so groups collapsed into boxes know their ports.
+func (c *Circuit) Animate(allInputs []string) (string, error) {
+	if _, _, _, err := c.view(); err != nil {
+		return "", err
+	}
+	var res *svg.Animation
+	c.Run(allInputs, func(i int) {
+		if res == nil {
+			inputs, outputs, components, _ := c.view()
+			res = svg.NewAnimation(inputs, outputs, components, c.Config)
+		}
+		res.Frame(sfmt.Sprintf("step %d: %s", i, c.StringForUnitTest()))
+	})
+	if res == nil {
+		inputs, outputs, components, _ := c.view()
+		res = svg.NewAnimation(inputs, outputs, components, c.Config)
+	}
+	return res.HTML()
+}
+
 // Simulate simulates the circuit.
 func (c *Circuit) Simulate() []string {
+	return c.SimulateInputs(c.Vectors())
+}
+
+// Vectors returns the input vectors used by Simulate, one character per input.
+//
+// Without Config.SimulateInputs, they are all vectors up to Config.ExhaustiveInputs inputs,
+// and Config.RandomVectors random vectors that pass the input validations otherwise.
+func (c *Circuit) Vectors() []string {
 	if len(c.Config.SimulateInputs) > 0 {
-		return c.SimulateInputs(c.Config.SimulateInputs)
+		return c.Config.SimulateInputs
 	}
-	if !c.Config.DrawSingleGraph && len(c.Inputs) <= 7 {
-		return c.simulate(0)
+	exhaustive := c.Config.ExhaustiveInputs
+	if exhaustive == 0 {
+		exhaustive = 7
 	}
-	rand := rand.New(rand.NewPCG(42, 1024))
 	var res []string
-	for i := 0; i < 10; i++ {
-		for _, input := range c.Inputs {
-			input.Bit.SilentSet(rand.IntN(2) == 1)
+	if !c.Config.DrawSingleGraph && len(c.Inputs) <= exhaustive {
+		for i := 0; i < 1<<len(c.Inputs); i++ {
+			var one []string
+			for j := range c.Inputs {
+				one = append(one, wire.BoolToString(i>>(len(c.Inputs)-1-j)&1 == 1))
+			}
+			res = append(res, strings.Join(one, ""))
 		}
-		res = append(res, c.simulate(len(c.Inputs))...)
-		if c.Config.DrawSingleGraph {
+		return res
+	}
+	count, seed := c.Config.RandomVectors, c.Config.RandomSeed
+	if count == 0 {
+		count = 10
+	}
+	if seed == 0 {
+		seed = 42
+	}
+	if c.Config.DrawSingleGraph {
+		count = 1
+	}
+	// the search sets the inputs, so restore them afterwards
+	previous := c.inputs()
+	defer c.SetInputs(previous)
+	rand := rand.New(rand.NewPCG(seed, 1024))
+	for range count {
+		one, ok := c.randomValid(rand)
+		if !ok {
 			break
 		}
+		res = append(res, one)
 	}
 	return res
 }
 
-// SimulateInputs simulates the circuit for the given inputs.
-func (c *Circuit) SimulateInputs(allInputs []string) []string {
-	var res []string
-	for _, inputs := range allInputs {
-		for i, input := range inputs {
-			c.Inputs[i].Bit.SilentSet(input == '1')
+// MaxRandomTries is the most vectors tried when searching for a random vector that passes the input validations.
+const MaxRandomTries = 1 << 12
+
+// randomValid returns a random vector that passes the input validations, or false if none was found.
+//
+// Each input tries a random value first and then the other one, depth first,
+// so the validations only backtrack over the inputs they reject.
+func (c *Circuit) randomValid(rand *rand.Rand) (string, bool) {
+	tries := 0
+	var search func(i int) bool
+	search = func(i int) bool {
+		if i == len(c.Inputs) {
+			tries++
+			return c.IsValid()
 		}
-		res = append(res, c.simulate(len(c.Inputs))...)
+		first := rand.IntN(2) == 1
+		for _, v := range []bool{first, !first} {
+			if tries >= MaxRandomTries {
+				return false
+			}
+			c.Inputs[i].Bit.SilentSet(v)
+			if search(i + 1) {
+				return true
+			}
+		}
+		return false
+	}
+	if !search(0) {
+		return "", false
+	}
+	return c.inputs(), true
+}
+
+// inputs returns the current input values, one character per input.
+func (c *Circuit) inputs() string {
+	var res string
+	for _, input := range c.Inputs {
+		res += wire.BoolToString(input.Bit.SilentGet())
 	}
 	return res
 }
 
-func (c *Circuit) simulate(index int) []string {
-	if index >= len(c.Inputs) {
-		valid := true
-		for _, fn := range c.InputValidations {
-			if !fn() {
-				valid = false
-				break
-			}
+// Run updates the circuit for each valid input and calls fn with the index of the input,
+// until Config.TimeBudget runs out.
+func (c *Circuit) Run(allInputs []string, fn func(i int)) {
+	start := time.Now()
+	for i, inputs := range allInputs {
+		if c.Config.TimeBudget > 0 && time.Since(start) > c.Config.TimeBudget {
+			return
 		}
-		if !valid {
-			return nil
+		c.SetInputs(inputs)
+		if !c.IsValid() {
+			continue
 		}
 		c.Update()
-		if c.Config.DrawGraph {
-			return []string{c.Graph()}
-		}
-		if c.Config.IsUnitTest {
-			return []string{c.StringForUnitTest()}
-		}
-		return []string{c.String()}
+		fn(i)
 	}
+}
+
+// SimulateInputs simulates the circuit for the given inputs.
+func (c *Circuit) SimulateInputs(allInputs []string) []string {
 	var res []string
-	for _, value := range []bool{false, true} {
-		c.Inputs[index].Bit.SilentSet(value)
-		res = append(res, c.simulate(index+1)...)
-	}
+	c.Run(allInputs, func(int) {
+		res = append(res, c.render())
+	})
 	return res
 }
+
+// SetInputs sets the inputs without updating the components, one character per input.
+func (c *Circuit) SetInputs(inputs string) {
+	for i, input := range inputs {
+		c.Inputs[i].Bit.SilentSet(input == '1')
+	}
+}
+
+// IsValid returns whether the current inputs pass all input validations.
+func (c *Circuit) IsValid() bool {
+	for _, fn := range c.InputValidations {
+		if !fn() {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Circuit) render() string {
+	if c.Config.DrawSVG {
+		return c.SVG()
+	}
+	if c.Config.DrawGraph {
+		return c.Graph()
+	}
+	if c.Config.IsUnitTest {
+		return c.StringForUnitTest()
+	}
+	return c.String()
+}
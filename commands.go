@@ -0,0 +1,557 @@
+package main
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kssilveira/circuit-engine/asm"
+	"github.com/kssilveira/circuit-engine/behavior"
+	"github.com/kssilveira/circuit-engine/circuit"
+	"github.com/kssilveira/circuit-engine/component"
+	"github.com/kssilveira/circuit-engine/format/blif"
+	"github.com/kssilveira/circuit-engine/format/jsonfmt"
+	"github.com/kssilveira/circuit-engine/format/memimage"
+	"github.com/kssilveira/circuit-engine/format/spice"
+	"github.com/kssilveira/circuit-engine/format/verilog"
+	"github.com/kssilveira/circuit-engine/group"
+	"github.com/kssilveira/circuit-engine/jointwire"
+	"github.com/kssilveira/circuit-engine/lib"
+	"github.com/kssilveira/circuit-engine/netlist"
+	"github.com/kssilveira/circuit-engine/repl"
+	"github.com/kssilveira/circuit-engine/server"
+	"github.com/kssilveira/circuit-engine/session"
+	"github.com/kssilveira/circuit-engine/trace"
+	"github.com/kssilveira/circuit-engine/transistor"
+	"github.com/kssilveira/circuit-engine/tui"
+	"github.com/kssilveira/circuit-engine/wire"
+)
+
+const vectorsHelp = "comma-separated input vectors, one character per input, such as 00,01; defaults to the vectors of the example, or all vectors up to --exhaustive_inputs inputs and --random_vectors random vectors that pass the input validations otherwise"
+
+// checkFormat returns a usage error unless the format is one of the valid ones.
+func checkFormat(format string, valid ...string) error {
+	if !slices.Contains(valid, format) {
+		return usageErrorf("invalid --format %q, valid formats are %q", format, valid)
+	}
+	return nil
+}
+
+// entry is an example in the list command.
+type entry struct {
+	Name string `json:"name"`
+	lib.Meta
+}
+
+func list(args []string, out io.Writer) error {
+	f := newFlags("list", false)
+	format := f.String("format", "text", "text prints one name per line, table adds the category and description, json prints the metadata")
+	category := f.String("category", "", "list only the examples in this category, such as gate, arith, memory or cpu")
+	if err := f.parse(args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format, "text", "table", "json"); err != nil {
+		return err
+	}
+	entries := []entry{}
+	for _, name := range lib.ExampleNames() {
+		meta, _ := lib.Info(name)
+		if *category == "" || string(meta.Category) == *category {
+			entries = append(entries, entry{name, meta})
+		}
+	}
+	if *format == "json" {
+		return printJSON(out, entries)
+	}
+	var res strings.Builder
+	writer := tabwriter.NewWriter(&res, 0, 0, 2, ' ', 0)
+	for _, one := range entries {
+		if *format == "text" {
+			fmt.Fprintln(writer, one.Name)
+			continue
+		}
+		kind := "combinational"
+		if one.Sequential {
+			kind = "sequential"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", one.Name, one.Category, kind, one.Description)
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	return write(out, "", res.String())
+}
+
+// step contains the input and output values after one update.
+type step struct {
+	Inputs  []session.Value `json:"inputs"`
+	Outputs []session.Value `json:"outputs"`
+}
+
+// steps simulates the circuit and returns the values of each valid vector.
+func steps(c *circuit.Circuit) []step {
+	var res []step
+	c.Run(c.Vectors(), func(int) {
+		res = append(res, step{Inputs: session.Values(c.Inputs), Outputs: session.Values(c.Outputs)})
+	})
+	return res
+}
+
+func printJSON(out io.Writer, v any) error {
+	data, err := json.MarshalIndent(v, "", " ")
+	if err != nil {
+		return fmt.Errorf("json.MarshalIndent got err %v", err)
+	}
+	return write(out, "", string(data)+"\n")
+}
+
+func simulate(args []string, out io.Writer) error {
+	f := newFlags("simulate", true)
+	format := f.String("format", "text", "text prints the circuit after each step, unit prints one line per step such as 10=>10, json prints the values of each step, trace prints the registers, disassembled instruction and control lines of alu.WithCPU at each rising edge of the clock e")
+	f.printing()
+	f.simulation(vectorsHelp)
+	f.random()
+	if err := f.parse(args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format, "text", "unit", "json", "trace"); err != nil {
+		return err
+	}
+	f.cfg.IsUnitTest = *format == "unit"
+	c, err := f.build()
+	if err != nil {
+		return err
+	}
+	switch *format {
+	case "json":
+		return printJSON(out, steps(c))
+	case "unit":
+		return write(out, "", strings.Join(c.Simulate(), "\n")+"\n")
+	case "trace":
+		res, err := trace.Run(c, c.Vectors(), "e")
+		if err != nil {
+			return err
+		}
+		return write(out, "", res)
+	}
+	return write(out, "", strings.Join(c.Simulate(), "\n\n")+"\n")
+}
+
+func truthtable(args []string, out io.Writer) error {
+	f := newFlags("truthtable", true)
+	format := f.String("format", "text", "text aligns the columns, csv and markdown print a table in that format")
+	f.simulation(vectorsHelp)
+	f.random()
+	if err := f.parse(args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format, "text", "csv", "markdown"); err != nil {
+		return err
+	}
+	c, err := f.build()
+	if err != nil {
+		return err
+	}
+	var rows [][]string
+	for _, one := range steps(c) {
+		var row []string
+		for _, v := range append(one.Inputs, one.Outputs...) {
+			row = append(row, wire.BoolToString(v.Value))
+		}
+		rows = append(rows, row)
+	}
+	var header []string
+	for _, w := range append(append([]*wire.Wire{}, c.Inputs...), c.Outputs...) {
+		header = append(header, w.Name)
+	}
+	switch *format {
+	case "csv":
+		writer := csv.NewWriter(out)
+		if err := writer.WriteAll(append([][]string{header}, rows...)); err != nil {
+			return fmt.Errorf("csv.WriteAll got err %v", err)
+		}
+		return nil
+	case "markdown":
+		var res []string
+		separator := make([]string, len(header))
+		for i, name := range header {
+			header[i] = strings.ReplaceAll(name, "|", `\|`)
+			separator[i] = "---"
+		}
+		for _, row := range append([][]string{header, separator}, rows...) {
+			res = append(res, "| "+strings.Join(row, " | ")+" |")
+		}
+		return write(out, "", strings.Join(res, "\n")+"\n")
+	}
+	// the text table separates the inputs from the outputs
+	var res []string
+	for _, row := range append([][]string{header}, rows...) {
+		var cells []string
+		for i, cell := range row {
+			if i == len(c.Inputs) {
+				cells = append(cells, "|")
+			}
+			cells = append(cells, cell+strings.Repeat(" ", len(header[i])-len(cell)))
+		}
+		res = append(res, strings.TrimRight(strings.Join(cells, " "), " "))
+	}
+	return write(out, "", strings.Join(res, "\n")+"\n")
+}
+
+func drawCommand(args []string, out io.Writer) error {
+	f := newFlags("draw", true)
+	format := f.String("format", "svg", "dot prints a graphviz graph, svg an image without graphviz and html a page animating each step")
+	file := f.String("out", "", "write to this file instead of the standard output, with %d in the name for one dot or svg file per step")
+	f.printing()
+	f.simulation("comma-separated input vectors, one character per input, defaults to the vectors of the example or all inputs at 0; dot and svg draw the last step")
+	f.BoolVar(&f.cfg.DrawNodes, "draw_nodes", true, "draw nodes")
+	f.BoolVar(&f.cfg.DrawEdges, "draw_edges", true, "draw edges")
+	f.BoolVar(&f.cfg.DrawShapePoint, "draw_shape_point", false, "draw shape point")
+	if err := f.parse(args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format, "dot", "svg", "html"); err != nil {
+		return err
+	}
+	perStep := strings.Contains(*file, "%d")
+	if perStep && *format == "html" {
+		return usageErrorf("--out %q cannot contain %%d with --format html, which draws all steps in one page", *file)
+	}
+	f.cfg.DrawGraph = *format == "dot"
+	f.cfg.DrawSVG = *format == "svg"
+	c, err := f.build()
+	if err != nil {
+		return err
+	}
+	vectors := f.vectors()
+	if vectors == nil {
+		vectors = []string{""}
+	}
+	if *format == "html" {
+		page, err := c.Animate(vectors)
+		if err != nil {
+			return err
+		}
+		return write(out, *file, page)
+	}
+	res := c.SimulateInputs(vectors)
+	if len(res) == 0 {
+		return fmt.Errorf("all inputs %q fail validation", vectors)
+	}
+	if !perStep {
+		return write(out, *file, res[len(res)-1]+"\n")
+	}
+	for i, one := range res {
+		if err := write(out, strings.ReplaceAll(*file, "%d", strconv.Itoa(i)), one+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// statistics contains the number of nets and components of a circuit.
+type statistics struct {
+	Inputs      int `json:"inputs"`
+	Outputs     int `json:"outputs"`
+	Nets        int `json:"nets"`
+	Groups      int `json:"groups"`
+	Transistors int `json:"transistors"`
+	JointWires  int `json:"joint_wires"`
+	Blocks      int `json:"blocks"`
+	// Depth is the number of nested groups.
+	Depth int `json:"depth"`
+	// Kinds contains the number of groups of each kind, such as "AND".
+	Kinds map[string]int `json:"kinds"`
+}
+
+func (s *statistics) count(components []component.Component, depth int) {
+	for _, one := range components {
+		switch one := one.(type) {
+		case *group.Group:
+			s.Groups++
+			s.Depth = max(s.Depth, depth+1)
+			if one.Kind != "" {
+				s.Kinds[one.Kind]++
+			}
+			s.count(one.Components, depth+1)
+		case *transistor.Transistor:
+			s.Transistors++
+		case *jointwire.JointWire:
+			s.JointWires++
+		case *behavior.Block:
+			s.Blocks++
+		}
+	}
+}
+
+func stats(args []string, out io.Writer) error {
+	f := newFlags("stats", true)
+	format := f.String("format", "text", "text prints one count per line, json prints an object")
+	f.StringVar(&f.cfg.Focus, "focus", "", "count only the group at this path, such as CPU/RAM")
+	if err := f.parse(args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format, "text", "json"); err != nil {
+		return err
+	}
+	c, err := f.build()
+	if err != nil {
+		return err
+	}
+	res := statistics{Inputs: len(c.Inputs), Outputs: len(c.Outputs), Nets: len(netlist.New(c).Nets), Kinds: map[string]int{}}
+	components := c.Components
+	if f.cfg.Focus != "" {
+		g, err := c.Find(f.cfg.Focus)
+		if err != nil {
+			return err
+		}
+		// Ports knows the readers after an update
+		c.Update()
+		ins, outs := g.Ports(c.Outputs)
+		res.Inputs, res.Outputs = len(ins), len(outs)
+		res.Nets = len(netlist.New(&circuit.Circuit{Inputs: ins, Outputs: outs, Components: []component.Component{g}}).Nets)
+		components = g.Components
+	}
+	res.count(components, 0)
+	if *format == "json" {
+		return printJSON(out, res)
+	}
+	lines := []string{
+		fmt.Sprintf("inputs %d", res.Inputs),
+		fmt.Sprintf("outputs %d", res.Outputs),
+		fmt.Sprintf("nets %d", res.Nets),
+		fmt.Sprintf("groups %d", res.Groups),
+		fmt.Sprintf("transistors %d", res.Transistors),
+		fmt.Sprintf("joint_wires %d", res.JointWires),
+		fmt.Sprintf("blocks %d", res.Blocks),
+		fmt.Sprintf("depth %d", res.Depth),
+	}
+	var kinds []string
+	for kind := range res.Kinds {
+		kinds = append(kinds, kind)
+	}
+	slices.Sort(kinds)
+	for _, kind := range kinds {
+		lines = append(lines, fmt.Sprintf("kind %s %d", kind, res.Kinds[kind]))
+	}
+	return write(out, "", strings.Join(lines, "\n")+"\n")
+}
+
+func test(args []string, out io.Writer) error {
+	f := newFlags("test", true)
+	want := f.String("want", "", "file with one expected step per line in the simulate --format unit format, such as 10=>10; empty lines and lines starting with # are ignored")
+	if err := f.parse(args); err != nil {
+		return err
+	}
+	if *want == "" {
+		return usageErrorf("missing --want")
+	}
+	data, err := os.ReadFile(*want)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", *want, err)
+	}
+	f.cfg.IsUnitTest = true
+	c, err := f.build()
+	if err != nil {
+		return err
+	}
+	total, failed := 0, 0
+	report := func(i int, format string, a ...any) error {
+		failed++
+		_, err := fmt.Fprintf(out, "%s:%d: %s\n", *want, i+1, fmt.Sprintf(format, a...))
+		return err
+	}
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		total++
+		inputs, _, ok := strings.Cut(line, "=>")
+		if !ok || len(inputs) != len(c.Inputs) {
+			if err := report(i, "want %d inputs followed by => and the outputs, got %q", len(c.Inputs), line); err != nil {
+				return err
+			}
+			continue
+		}
+		c.SetInputs(inputs)
+		if !c.IsValid() {
+			if err := report(i, "inputs %s fail validation", inputs); err != nil {
+				return err
+			}
+			continue
+		}
+		c.Update()
+		if got := c.StringForUnitTest(); got != line {
+			if err := report(i, "want %s got %s", line, got); err != nil {
+				return err
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d steps failed", failed, total)
+	}
+	_, err = fmt.Fprintf(out, "ok %d steps\n", total)
+	return err
+}
+
+func export(args []string, out io.Writer) error {
+	f := newFlags("export", true)
+	format := f.String("format", "json", "json, verilog, testbench (a Verilog testbench replaying the inputs), blif or spice (the transistor network driven by the inputs)")
+	file := f.String("out", "", "write to this file instead of the standard output")
+	f.gateLevel()
+	f.simulation(vectorsHelp + "; replayed by the testbench and spice formats")
+	f.random()
+	if err := f.parse(args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format, "json", "verilog", "testbench", "blif", "spice"); err != nil {
+		return err
+	}
+	c, err := f.build()
+	if err != nil {
+		return err
+	}
+	var res string
+	switch *format {
+	case "json":
+		var data []byte
+		data, err = jsonfmt.Marshal(c)
+		res = string(data) + "\n"
+	case "verilog":
+		res, err = verilog.Export(c)
+	case "testbench":
+		res, err = verilog.Testbench(c, c.Vectors())
+	case "blif":
+		res, err = blif.Export(c)
+	case "spice":
+		res, err = spice.Export(c, c.Vectors())
+	}
+	if err != nil {
+		return err
+	}
+	return write(out, *file, res)
+}
+
+func asmCommand(args []string, out io.Writer) error {
+	f := newFlags("asm", false)
+	file := f.String("out", "", "write the image to this file, as Intel-HEX (.hex), raw binary (.bin) or text otherwise, instead of the standard output as text, see --ram_image")
+	bits := f.Int("address_bits", 4, "address bits of the CPU, see alu.WithCPU")
+	disassemble := f.Bool("disassemble", false, "read an image, in the formats of --ram_image, and print its statements instead")
+	var name string
+	f.arg, f.argName = &name, "file"
+	if err := f.parse(args); err != nil {
+		return err
+	}
+	var data []byte
+	var err error
+	if name != "" {
+		data, err = os.ReadFile(name)
+	} else {
+		data, err = io.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		return fmt.Errorf("read %s: %w", cmp.Or(name, "stdin"), err)
+	}
+	if *disassemble {
+		words, err := memimage.Read(name, data)
+		if err != nil {
+			return err
+		}
+		return write(out, *file, asm.Listing(words, *bits))
+	}
+	words, err := asm.Assemble(string(data), *bits)
+	if err != nil {
+		return err
+	}
+	image, err := memimage.Write(*file, words)
+	if err != nil {
+		return err
+	}
+	return write(out, *file, string(image))
+}
+
+func replCommand(args []string, out io.Writer) error {
+	f := newFlags("repl", false)
+	f.IntVar(&f.cfg.MaxPrintDepth, "max_print_depth", -1, "collapse groups at this depth into boxes when printing, -1 prints all groups")
+	f.gateLevel()
+	if err := f.parse(args); err != nil {
+		return err
+	}
+	r := repl.New(f.cfg)
+	// only prompt on terminals, so piped scripts print just the results
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		r.Prompt = "> "
+	}
+	return r.Run(os.Stdin, out)
+}
+
+func tuiCommand(args []string, out io.Writer) error {
+	f := newFlags("tui", true)
+	probes := f.String("probes", "", "comma-separated name patterns of the nets to watch, such as d*,Rr*")
+	if err := f.parse(args); err != nil {
+		return err
+	}
+	c, err := f.build()
+	if err != nil {
+		return err
+	}
+	s, err := newSession(c, *probes)
+	if err != nil {
+		return err
+	}
+	state, err := stty("-g")
+	if err != nil {
+		return err
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		return err
+	}
+	defer stty(strings.TrimSpace(state))
+	return tui.Run(s, os.Stdin, out)
+}
+
+func newSession(c *circuit.Circuit, probes string) (*session.Session, error) {
+	res := session.New(c)
+	for _, pattern := range strings.Split(probes, ",") {
+		if pattern == "" {
+			continue
+		}
+		if _, err := res.Watch(pattern); err != nil {
+			return nil, usageError{err}
+		}
+	}
+	return res, nil
+}
+
+// stty runs stty on the terminal, which puts it in raw mode without dependencies.
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	res, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("stty %s got err %v", strings.Join(args, " "), err)
+	}
+	return string(res), nil
+}
+
+func serve(args []string, _ io.Writer) error {
+	f := newFlags("serve", false)
+	addr := f.String("addr", "localhost:8080", "address to listen on")
+	srv := server.New()
+	f.DurationVar(&srv.IdleTimeout, "idle_timeout", server.IdleTimeout, "remove sessions that are not used for this duration, 0 keeps them")
+	if err := f.parse(args); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "serving on http://%s\n", *addr)
+	return http.ListenAndServe(*addr, srv.Handler())
+}
@@ -3,11 +3,12 @@ package component
 
 import (
 	"github.com/kssilveira/circuit-engine/config"
+	"github.com/kssilveira/circuit-engine/graphid"
 )
 
 // Component contains the component interface.
 type Component interface {
 	Update(updateReaders bool)
 	String(depth int, cfg config.Config) string
-	Graph(depth int, cfg config.Config) string
+	Graph(depth int, cfg config.Config, ids *graphid.IDs) string
 }
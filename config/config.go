@@ -1,14 +1,32 @@
 // Package config encapsulates configuration.
 package config
 
+import "time"
+
 // Config contains configuration.
 type Config struct {
 	MaxPrintDepth   int
 	DrawGraph       bool
+	DrawSVG         bool
 	DrawSingleGraph bool
 	DrawNodes       bool
 	DrawShapePoint  bool
 	DrawEdges       bool
 	IsUnitTest      bool
 	SimulateInputs  []string
+	GateLevel       bool
+	Focus           string
+	// RandomVectors is the number of random vectors, 10 when 0.
+	RandomVectors int
+	// RandomSeed seeds the random vectors, 42 when 0.
+	RandomSeed uint64
+	// ExhaustiveInputs is the most inputs simulated with all vectors instead of random ones, 7 when 0 and none when negative.
+	ExhaustiveInputs int
+	// TimeBudget stops the simulation after this duration when positive.
+	TimeBudget time.Duration
+	// Behavioral contains path.Match patterns of group paths, such as "*" for all groups or "CPU/RAM",
+	// whose library blocks are built as behavioral models instead of transistors.
+	Behavioral []string
+	// Transistors contains the patterns of the groups inside behavioral ones that are still built with transistors.
+	Transistors []string
 }
@@ -4,17 +4,34 @@ package draw
 import (
 	"strings"
 
+	"github.com/kssilveira/circuit-engine/graphid"
 	"github.com/kssilveira/circuit-engine/sfmt"
 	"github.com/kssilveira/circuit-engine/wire"
 )
 
 // EdgeColor returns graphviz edge color.
 func EdgeColor(a, b *wire.Wire) string {
-	EdgeColor := "blue"
+	return sfmt.Sprintf(`[color="%s"]`, Color(a, b))
+}
+
+// Color returns red when either wire is true and blue otherwise.
+func Color(a, b *wire.Wire) string {
 	if a.Bit.Get(nil) || b.Bit.Get(nil) {
-		EdgeColor = "red"
+		return "red"
+	}
+	return "blue"
+}
+
+// RecordEscape escapes the characters that are special in graphviz record labels.
+func RecordEscape(label string) string {
+	var res strings.Builder
+	for _, r := range label {
+		if strings.ContainsRune(`{}|<> "\`, r) {
+			res.WriteRune('\\')
+		}
+		res.WriteRune(r)
 	}
-	return sfmt.Sprintf(`[color="%s"]`, EdgeColor)
+	return res.String()
 }
 
 // StringPrefix returns string prefix for print.
@@ -26,3 +43,15 @@ func StringPrefix(depth int) string {
 func GraphPrefix(depth int) string {
 	return strings.Repeat(" ", depth)
 }
+
+// WireNode returns the graphviz ID of the wire and its node declaration, which is empty after the first use.
+func WireNode(ids *graphid.IDs, w *wire.Wire, shapePoint bool) (string, string) {
+	id, ok := ids.Wire(w.Name, w)
+	if !ok {
+		return id, ""
+	}
+	if shapePoint {
+		return id, sfmt.Sprintf(`"%s" [label= "";shape=point];`, id)
+	}
+	return id, sfmt.Sprintf(`"%s" [label="%v"];`, id, *w)
+}
@@ -0,0 +1,176 @@
+package svg
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kssilveira/circuit-engine/component"
+	"github.com/kssilveira/circuit-engine/config"
+	"github.com/kssilveira/circuit-engine/draw"
+	"github.com/kssilveira/circuit-engine/sfmt"
+	"github.com/kssilveira/circuit-engine/wire"
+)
+
+// Animation contains a drawing and the wire values of each frame.
+type Animation struct {
+	svg    string
+	canvas *canvas
+	// labels contains the current text of each label, used to store only the changes
+	labels []string
+	frames []frame
+}
+
+// frame contains the wire values of one simulation step.
+type frame struct {
+	Title string `json:"title"`
+	// Labels contains the labels that changed since the previous frame
+	Labels map[int]string `json:"labels"`
+	// Fills and Strokes contain one character per element, "r" for red and "b" for blue
+	Fills   string `json:"fills"`
+	Strokes string `json:"strokes"`
+}
+
+// NewAnimation lays out and draws the circuit once, with the current wire values.
+func NewAnimation(inputs, outputs []*wire.Wire, components []component.Component, cfg config.Config) *Animation {
+	res := &Animation{}
+	res.svg, res.canvas = render(inputs, outputs, components, cfg)
+	for _, w := range res.canvas.labels {
+		res.labels = append(res.labels, sfmt.Sprintf("%v", *w))
+	}
+	return res
+}
+
+// Frame adds a frame with the current wire values.
+func (a *Animation) Frame(title string) {
+	one := frame{Title: title, Labels: map[int]string{}}
+	for i, w := range a.canvas.labels {
+		label := sfmt.Sprintf("%v", *w)
+		if len(a.frames) == 0 || label != a.labels[i] {
+			one.Labels[i] = label
+		}
+		a.labels[i] = label
+	}
+	var fills, strokes strings.Builder
+	for _, w := range a.canvas.fills {
+		fills.WriteByte(draw.Color(w, w)[0])
+	}
+	for _, s := range a.canvas.strokes {
+		strokes.WriteByte(draw.Color(s[0], s[1])[0])
+	}
+	one.Fills, one.Strokes = fills.String(), strokes.String()
+	a.frames = append(a.frames, one)
+}
+
+// HTML returns a self-contained page that shows the frames, with play and step controls.
+func (a *Animation) HTML() (string, error) {
+	// json escapes "<", so the frames cannot close the script element
+	frames, err := json.Marshal(a.frames)
+	if err != nil {
+		return "", fmt.Errorf("json.Marshal got err %v", err)
+	}
+	return strings.Join([]string{
+		`<!DOCTYPE html>`,
+		`<html>`,
+		`<head>`,
+		`<meta charset="utf-8">`,
+		`<title>circuit-engine</title>`,
+		`<style>`,
+		`body { font-family: sans-serif; margin: 0; }`,
+		`#controls { position: sticky; top: 0; background: white; padding: 8px; border-bottom: 1px solid gray; }`,
+		`</style>`,
+		`</head>`,
+		`<body>`,
+		`<div id="controls">`,
+		`<button id="first">|&lt;</button>`,
+		`<button id="previous">&lt;</button>`,
+		`<button id="play">play</button>`,
+		`<button id="next">&gt;</button>`,
+		`<button id="last">&gt;|</button>`,
+		`<input id="frame" type="range" min="0" value="0">`,
+		`<label>delay <input id="delay" type="number" min="50" step="50" value="500"> ms</label>`,
+		`<span id="title"></span>`,
+		`</div>`,
+		a.svg,
+		`<script>`,
+		`const frames = ` + string(frames) + `;`,
+		animationScript,
+		`</script>`,
+		`</body>`,
+		`</html>`,
+		``,
+	}, "\n"), nil
+}
+
+const animationScript = `const colors = {r: "red", b: "blue"};
+const slider = document.getElementById("frame");
+const play = document.getElementById("play");
+slider.max = Math.max(frames.length - 1, 0);
+let current = 0;
+let timer = null;
+
+function show(index) {
+  if (frames.length === 0) {
+    return;
+  }
+  current = Math.min(Math.max(index, 0), frames.length - 1);
+  // labels only store the changes, so replay them from the first frame
+  const labels = {};
+  for (let i = 0; i <= current; i++) {
+    Object.assign(labels, frames[i].labels);
+  }
+  for (const [id, label] of Object.entries(labels)) {
+    document.getElementById("l" + id).textContent = label;
+  }
+  const frame = frames[current];
+  for (let i = 0; i < frame.fills.length; i++) {
+    document.getElementById("f" + i).setAttribute("fill", colors[frame.fills[i]]);
+  }
+  for (let i = 0; i < frame.strokes.length; i++) {
+    document.getElementById("s" + i).setAttribute("stroke", colors[frame.strokes[i]]);
+  }
+  slider.value = current;
+  document.getElementById("title").textContent = (current + 1) + "/" + frames.length + " " + frame.title;
+}
+
+function stop() {
+  clearInterval(timer);
+  timer = null;
+  play.textContent = "play";
+}
+
+play.onclick = () => {
+  if (timer !== null) {
+    stop();
+    return;
+  }
+  if (current === frames.length - 1) {
+    show(0);
+  }
+  play.textContent = "pause";
+  timer = setInterval(() => {
+    if (current >= frames.length - 1) {
+      stop();
+      return;
+    }
+    show(current + 1);
+  }, Number(document.getElementById("delay").value));
+};
+document.getElementById("first").onclick = () => { stop(); show(0); };
+document.getElementById("previous").onclick = () => { stop(); show(current - 1); };
+document.getElementById("next").onclick = () => { stop(); show(current + 1); };
+document.getElementById("last").onclick = () => { stop(); show(frames.length - 1); };
+slider.oninput = () => { stop(); show(Number(slider.value)); };
+document.onkeydown = (event) => {
+  if (event.key === "ArrowLeft") {
+    stop();
+    show(current - 1);
+  } else if (event.key === "ArrowRight") {
+    stop();
+    show(current + 1);
+  } else if (event.key === " " && event.target.tagName !== "BUTTON") {
+    event.preventDefault();
+    play.onclick();
+  }
+};
+show(0);`
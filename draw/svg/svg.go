@@ -0,0 +1,489 @@
+// Package svg lays out and draws circuits as SVG without graphviz.
+package svg
+
+import (
+	"html"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kssilveira/circuit-engine/behavior"
+	"github.com/kssilveira/circuit-engine/component"
+	"github.com/kssilveira/circuit-engine/config"
+	"github.com/kssilveira/circuit-engine/draw"
+	"github.com/kssilveira/circuit-engine/group"
+	"github.com/kssilveira/circuit-engine/jointwire"
+	"github.com/kssilveira/circuit-engine/sfmt"
+	"github.com/kssilveira/circuit-engine/transistor"
+	"github.com/kssilveira/circuit-engine/wire"
+)
+
+const (
+	pad       = 10.0
+	gap       = 20.0
+	title     = 16.0
+	fontSize  = 12.0
+	charWidth = 7.0
+	portGap   = 16.0
+)
+
+// Render returns the SVG drawing of the circuit.
+//
+// Groups become dotted boxes laid out recursively: the components of each group are placed in
+// columns by the longest path from the group inputs, and each column is stacked vertically.
+// Transistors and joint wires become symbols, wires become labeled dots placed in the group
+// where they are first used, like in the graphviz graph, and edges are colored by value.
+// With Config.GateLevel, groups built by lib/gate are drawn with the standard gate shapes.
+// Groups at Config.MaxPrintDepth are drawn as boxes with the wires that cross their boundary as ports.
+func Render(inputs, outputs []*wire.Wire, components []component.Component, cfg config.Config) string {
+	res, _ := render(inputs, outputs, components, cfg)
+	return res
+}
+
+func render(inputs, outputs []*wire.Wire, components []component.Component, cfg config.Config) (string, *canvas) {
+	root, edges := build(inputs, outputs, components, cfg)
+	c := &canvas{}
+	var res []string
+	res = append(res,
+		sfmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%g" viewBox="0 0 %g %g" font-family="sans-serif" font-size="%g">`, root.w, root.h, root.w, root.h, fontSize),
+		sfmt.Sprintf(`<rect width="%g" height="%g" fill="white"/>`, root.w, root.h))
+	res = append(res, root.draw(c)...)
+	if cfg.DrawEdges {
+		for _, e := range edges {
+			res = append(res, e.draw(c))
+		}
+	}
+	res = append(res, "</svg>")
+	return strings.Join(res, "\n"), c
+}
+
+// canvas contains the elements that show wire values, so that animations can update them.
+type canvas struct {
+	// labels contains the wires of the text elements with ID "l<index>"
+	labels []*wire.Wire
+	// fills contains the wires of the elements with ID "f<index>" filled by value
+	fills []*wire.Wire
+	// strokes contains the wires of the elements with ID "s<index>" stroked by value
+	strokes [][2]*wire.Wire
+}
+
+func (c *canvas) label(w *wire.Wire) string {
+	c.labels = append(c.labels, w)
+	return sfmt.Sprintf(`id="l%d"`, len(c.labels)-1)
+}
+
+func (c *canvas) fill(w *wire.Wire) string {
+	c.fills = append(c.fills, w)
+	return sfmt.Sprintf(`id="f%d" fill="%s"`, len(c.fills)-1, draw.Color(w, w))
+}
+
+func (c *canvas) stroke(a, b *wire.Wire) string {
+	c.strokes = append(c.strokes, [2]*wire.Wire{a, b})
+	return sfmt.Sprintf(`id="s%d" stroke="%s"`, len(c.strokes)-1, draw.Color(a, b))
+}
+
+// build returns the root node with absolute positions and the edges.
+func build(inputs, outputs []*wire.Wire, components []component.Component, cfg config.Config) (*node, []edge) {
+	l := &layout{cfg: cfg, outputs: outputs, wires: map[*wire.Wire]*node{}}
+	root := &node{kind: "root"}
+	for _, input := range inputs {
+		l.wire(root, input).kind = "input"
+	}
+	for _, output := range outputs {
+		if n := l.wire(root, output); n.kind == "wire" {
+			n.kind = "output"
+		}
+	}
+	l.components(root, components, 0)
+	for _, e := range l.edges {
+		from, to, container := siblings(e.from, e.to)
+		if container != nil {
+			container.edges = append(container.edges, [2]*node{from, to})
+		}
+	}
+	root.size()
+	root.place(0, 0)
+	return root, l.edges
+}
+
+// node is a group, a symbol or a wire.
+type node struct {
+	kind  string
+	label string
+	// wire is the wire of wire, input and output nodes
+	wire     *wire.Wire
+	parent   *node
+	children []*node
+	// edges contains the edges between children, used for the layout
+	edges [][2]*node
+	// ins and outs contain the ports of gates and boxes
+	ins, outs []port
+	layer     int
+	x, y      float64
+	w, h      float64
+}
+
+// port is a port of a gate, labeled with its name, or of a box, labeled with its wire.
+type port struct {
+	label string
+	wire  *wire.Wire
+}
+
+type edge struct {
+	from, to *node
+	// the edge is colored by the value of a or b
+	a, b *wire.Wire
+	// from and to are the ports of the edge
+	fromPort, toPort int
+}
+
+type layout struct {
+	cfg config.Config
+	// outputs contains the circuit outputs, which are ports of the collapsed groups that write them
+	outputs []*wire.Wire
+	wires   map[*wire.Wire]*node
+	edges   []edge
+}
+
+func (l *layout) add(parent *node, n *node) *node {
+	n.parent = parent
+	parent.children = append(parent.children, n)
+	return n
+}
+
+// wire returns the node of the wire, adding it to the parent on first use.
+func (l *layout) wire(parent *node, w *wire.Wire) *node {
+	if n, ok := l.wires[w]; ok {
+		return n
+	}
+	label := sfmt.Sprintf("%v", *w)
+	if l.cfg.DrawShapePoint {
+		label = ""
+	}
+	l.wires[w] = l.add(parent, &node{kind: "wire", label: label, wire: w})
+	return l.wires[w]
+}
+
+// ports adds the ports of the gate or box and their edges.
+func (l *layout) ports(n *node, ins, outs []*wire.Wire, labels bool) {
+	for i, w := range ins {
+		one := port{sfmt.Sprintf("%v", *w), w}
+		if !labels {
+			one = port{label: group.PortNames[i]}
+		}
+		n.ins = append(n.ins, one)
+		l.edges = append(l.edges, edge{l.wire(n.parent, w), n, w, w, 0, i})
+	}
+	for i, w := range outs {
+		one := port{sfmt.Sprintf("%v", *w), w}
+		if !labels {
+			one = port{label: "out"}
+		}
+		n.outs = append(n.outs, one)
+		l.edges = append(l.edges, edge{n, l.wire(n.parent, w), w, w, i, 0})
+	}
+}
+
+func (l *layout) components(parent *node, components []component.Component, depth int) {
+	for _, one := range components {
+		switch one := one.(type) {
+		case *group.Group:
+			if l.cfg.MaxPrintDepth >= 0 && depth >= l.cfg.MaxPrintDepth {
+				ins, outs := one.Ports(l.outputs)
+				l.ports(l.add(parent, &node{kind: "box", label: one.Name}), ins, outs, true /* labels */)
+				continue
+			}
+			if l.cfg.GateLevel && one.IsGate() {
+				if !l.cfg.DrawNodes {
+					continue
+				}
+				l.ports(l.add(parent, &node{kind: "gate", label: one.Kind}), one.Ins, one.Outs, false /* labels */)
+				continue
+			}
+			l.components(l.add(parent, &node{kind: "group", label: one.Name}), one.Components, depth+1)
+		case *transistor.Transistor:
+			if !l.cfg.DrawNodes {
+				continue
+			}
+			t := l.add(parent, &node{kind: "transistor"})
+			for _, w := range []*wire.Wire{one.Base, one.Collector} {
+				l.edges = append(l.edges, edge{l.wire(parent, w), t, w, w, 0, 0})
+			}
+			for _, w := range []*wire.Wire{one.Emitter, one.CollectorOut} {
+				if w.Name == "Unused" {
+					continue
+				}
+				l.edges = append(l.edges, edge{t, l.wire(parent, w), w, w, 0, 0})
+			}
+		case *jointwire.JointWire:
+			if !l.cfg.DrawNodes {
+				continue
+			}
+			label := "OR"
+			if one.IsAnd {
+				label = "AND"
+			}
+			res := l.wire(parent, one.Res)
+			j := l.add(parent, &node{kind: "joint", label: label})
+			for _, w := range []*wire.Wire{one.A, one.B} {
+				l.edges = append(l.edges, edge{l.wire(parent, w), j, w, one.Res, 0, 0})
+			}
+			l.edges = append(l.edges, edge{j, res, one.Res, one.Res, 0, 0})
+		case *behavior.Block:
+			l.ports(l.add(parent, &node{kind: "box", label: one.Name}), one.Ins, one.Outs, true /* labels */)
+		}
+	}
+}
+
+// siblings returns the ancestors of both nodes that are children of the same node, and that node.
+func siblings(a, b *node) (*node, *node, *node) {
+	var path []*node
+	for n := a; n != nil; n = n.parent {
+		path = append(path, n)
+	}
+	for child := b; child.parent != nil; child = child.parent {
+		if i := slices.Index(path, child.parent); i > 0 {
+			if path[i-1] == child {
+				return nil, nil, nil
+			}
+			return path[i-1], child, child.parent
+		}
+	}
+	return nil, nil, nil
+}
+
+// size computes the size of the node and the layers of its children.
+func (n *node) size() {
+	switch n.kind {
+	case "transistor":
+		n.w, n.h = 24, 24
+		return
+	case "joint":
+		n.w, n.h = 30, 30
+		return
+	case "gate":
+		n.w, n.h = 60, 40
+		return
+	case "box":
+		width := func(ports []port) float64 {
+			res := 0.0
+			for _, port := range ports {
+				res = max(res, charWidth*float64(utf8.RuneCountInString(port.label)))
+			}
+			return res
+		}
+		n.w = max(width(n.ins)+width(n.outs)+3*pad, 2*pad+charWidth*float64(utf8.RuneCountInString(n.label)))
+		n.h = 2*pad + title + portGap*float64(max(len(n.ins), len(n.outs)))
+		return
+	case "wire", "input", "output":
+		n.w, n.h = 12+charWidth*float64(utf8.RuneCountInString(n.label)), 18
+		if n.kind != "wire" {
+			n.w += 20
+			n.h = 26
+		}
+		return
+	}
+	for _, child := range n.children {
+		child.size()
+	}
+	n.layers()
+	var widths, heights []float64
+	for _, child := range n.children {
+		for len(widths) <= child.layer {
+			widths = append(widths, 0)
+			heights = append(heights, 0)
+		}
+		widths[child.layer] = max(widths[child.layer], child.w)
+		heights[child.layer] += child.h + gap
+	}
+	n.w, n.h = 2*pad, 2*pad+title
+	for i := range widths {
+		n.w += widths[i]
+		if i > 0 {
+			n.w += gap
+		}
+		n.h = max(n.h, 2*pad+title+heights[i]-gap)
+	}
+	n.w = max(n.w, 2*pad+charWidth*float64(utf8.RuneCountInString(n.label)))
+	// positions relative to this node
+	x := pad
+	for i := range widths {
+		y := pad + title
+		for _, child := range n.children {
+			if child.layer == i {
+				child.x, child.y = x+(widths[i]-child.w)/2, y
+				y += child.h + gap
+			}
+		}
+		x += widths[i] + gap
+	}
+}
+
+// layers assigns each child the longest path from the children without inputs, ignoring back edges.
+func (n *node) layers() {
+	next := map[*node][]*node{}
+	for _, e := range n.edges {
+		next[e[0]] = append(next[e[0]], e[1])
+	}
+	state := map[*node]int{}
+	var order []*node
+	var visit func(*node)
+	visit = func(u *node) {
+		state[u] = 1
+		for _, v := range next[u] {
+			if state[v] == 0 {
+				visit(v)
+			}
+		}
+		state[u] = 2
+		order = append(order, u)
+	}
+	for _, child := range n.children {
+		if state[child] == 0 {
+			visit(child)
+		}
+	}
+	position := map[*node]int{}
+	for i, u := range order {
+		position[u] = i
+	}
+	last := 0
+	for i := len(order) - 1; i >= 0; i-- {
+		u := order[i]
+		for _, v := range next[u] {
+			// back edges point to nodes finished later
+			if position[v] < position[u] {
+				v.layer = max(v.layer, u.layer+1)
+			}
+		}
+		last = max(last, u.layer)
+	}
+	for _, child := range n.children {
+		switch child.kind {
+		case "input":
+			child.layer = 0
+		case "output":
+			child.layer = last + 1
+		}
+	}
+}
+
+// place converts the positions to absolute positions.
+func (n *node) place(x, y float64) {
+	n.x, n.y = x+n.x, y+n.y
+	for _, child := range n.children {
+		child.place(n.x, n.y)
+	}
+}
+
+func (n *node) draw(c *canvas) []string {
+	var res []string
+	label := html.EscapeString(n.label)
+	cx, cy := n.x+n.w/2, n.y+n.h/2
+	switch n.kind {
+	case "group":
+		res = append(res,
+			sfmt.Sprintf(`<rect x="%g" y="%g" width="%g" height="%g" fill="none" stroke="black" stroke-dasharray="2,2"/>`, n.x, n.y, n.w, n.h),
+			sfmt.Sprintf(`<text x="%g" y="%g" text-anchor="middle">%s</text>`, cx, n.y+pad+fontSize/2, label))
+	case "transistor":
+		res = append(res, sfmt.Sprintf(`<polygon points="%g,%g %g,%g %g,%g" fill="white" stroke="black"/>`, n.x, n.y, n.x+n.w, n.y, cx, n.y+n.h))
+	case "joint":
+		res = append(res,
+			sfmt.Sprintf(`<circle cx="%g" cy="%g" r="%g" fill="white" stroke="black"/>`, cx, cy, n.w/2),
+			sfmt.Sprintf(`<text x="%g" y="%g" text-anchor="middle" font-size="9">%s</text>`, cx, cy+3, label))
+	case "gate":
+		res = append(res, n.gate()...)
+	case "box":
+		res = append(res,
+			sfmt.Sprintf(`<rect x="%g" y="%g" width="%g" height="%g" fill="white" stroke="black"/>`, n.x, n.y, n.w, n.h),
+			sfmt.Sprintf(`<text x="%g" y="%g" text-anchor="middle">%s</text>`, cx, n.y+pad+fontSize/2, label))
+		for i, port := range n.ins {
+			res = append(res, sfmt.Sprintf(`<text %s x="%g" y="%g">%s</text>`, c.label(port.wire), n.x+pad/2, n.port(i)+fontSize/3, html.EscapeString(port.label)))
+		}
+		for i, port := range n.outs {
+			res = append(res, sfmt.Sprintf(`<text %s x="%g" y="%g" text-anchor="end">%s</text>`, c.label(port.wire), n.x+n.w-pad/2, n.outPort(i)+fontSize/3, html.EscapeString(port.label)))
+		}
+	case "wire":
+		res = append(res, sfmt.Sprintf(`<circle %s cx="%g" cy="%g" r="4"/>`, c.fill(n.wire), n.x+4, cy))
+		if label != "" {
+			res = append(res, sfmt.Sprintf(`<text %s x="%g" y="%g">%s</text>`, c.label(n.wire), n.x+12, cy+fontSize/3, label))
+		}
+	case "input", "output":
+		res = append(res,
+			sfmt.Sprintf(`<polygon points="%g,%g %g,%g %g,%g %g,%g %g,%g" fill="black"/>`,
+				n.x, n.y, n.x+n.w-10, n.y, n.x+n.w, cy, n.x+n.w-10, n.y+n.h, n.x, n.y+n.h))
+		if label != "" {
+			res = append(res, sfmt.Sprintf(`<text %s x="%g" y="%g" fill="white">%s</text>`, c.label(n.wire), n.x+6, cy+fontSize/3, label))
+		}
+	}
+	for _, child := range n.children {
+		res = append(res, child.draw(c)...)
+	}
+	return res
+}
+
+// port returns the height of the input port.
+func (n *node) port(i int) float64 {
+	switch {
+	case n.kind == "box":
+		return n.y + pad + title + portGap*(float64(i)+0.5)
+	case len(n.ins) == 1:
+		return n.y + n.h/2
+	}
+	return n.y + 10 + float64(i)*(n.h-20)/float64(len(n.ins)-1)
+}
+
+// outPort returns the height of the output port.
+func (n *node) outPort(i int) float64 {
+	if n.kind == "box" {
+		return n.y + pad + title + portGap*(float64(i)+0.5)
+	}
+	return n.y + n.h/2
+}
+
+// gate draws the standard shape of the gate, with a bubble for the inverted gates and the port names.
+func (n *node) gate() []string {
+	var res []string
+	x, y, cy := n.x+10, n.y, n.y+n.h/2
+	for i, port := range n.ins {
+		res = append(res,
+			sfmt.Sprintf(`<line x1="%g" y1="%g" x2="%g" y2="%g" stroke="black"/>`, n.x, n.port(i), x+8, n.port(i)),
+			sfmt.Sprintf(`<text x="%g" y="%g" font-size="8">%s</text>`, n.x, n.port(i)-2, port.label))
+	}
+	res = append(res,
+		sfmt.Sprintf(`<line x1="%g" y1="%g" x2="%g" y2="%g" stroke="black"/>`, x+36, cy, n.x+n.w, cy),
+		sfmt.Sprintf(`<text x="%g" y="%g" font-size="8" text-anchor="end">out</text>`, n.x+n.w, cy-2))
+	var body string
+	switch n.label {
+	case "NOT":
+		body = sfmt.Sprintf(`M%g,%g L%g,%g L%g,%g Z`, x, y+4, x+32, cy, x, y+n.h-4)
+	case "AND", "NAND":
+		body = sfmt.Sprintf(`M%g,%g L%g,%g A%g,%g 0 0 1 %g,%g L%g,%g Z`, x, y, x+16, y, n.h/2, n.h/2, x+16, y+n.h, x, y+n.h)
+	default:
+		body = sfmt.Sprintf(`M%g,%g Q%g,%g %g,%g Q%g,%g %g,%g Q%g,%g %g,%g Z`, x, y, x+24, y, x+36, cy, x+24, y+n.h, x, y+n.h, x+10, cy, x, y)
+	}
+	res = append(res, sfmt.Sprintf(`<path d="%s" fill="white" stroke="black"/>`, body))
+	switch n.label {
+	case "XOR":
+		res = append(res, sfmt.Sprintf(`<path d="M%g,%g Q%g,%g %g,%g" fill="none" stroke="black"/>`, x-5, y, x+5, cy, x-5, y+n.h))
+	case "NOT", "NAND", "NOR":
+		res = append(res, sfmt.Sprintf(`<circle cx="%g" cy="%g" r="4" fill="white" stroke="black"/>`, x+40, cy))
+	}
+	return res
+}
+
+func (e edge) draw(c *canvas) string {
+	x1, y1 := e.from.x+e.from.w, e.from.y+e.from.h/2
+	x2, y2 := e.to.x, e.to.y+e.to.h/2
+	if e.from.kind == "gate" || e.from.kind == "box" {
+		y1 = e.from.outPort(e.fromPort)
+	}
+	if e.to.kind == "gate" || e.to.kind == "box" {
+		y2 = e.to.port(e.toPort)
+	}
+	if e.from.kind == "transistor" {
+		x1, y1 = e.from.x+e.from.w/2, e.from.y+e.from.h
+	}
+	dx := max(30, (x2-x1)/2)
+	return sfmt.Sprintf(`<path %s d="M%g,%g C%g,%g %g,%g %g,%g" fill="none"/>`, c.stroke(e.a, e.b), x1, y1, x1+dx, y1, x2-dx, y2, x2, y2)
+}
@@ -0,0 +1,73 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kssilveira/circuit-engine/component"
+	"github.com/kssilveira/circuit-engine/config"
+	"github.com/kssilveira/circuit-engine/group"
+	"github.com/kssilveira/circuit-engine/lib/sum"
+	"github.com/kssilveira/circuit-engine/wire"
+)
+
+func TestLayout(t *testing.T) {
+	for _, cfg := range []config.Config{
+		{MaxPrintDepth: -1, DrawNodes: true, DrawEdges: true},
+		{MaxPrintDepth: -1, DrawNodes: true, DrawEdges: true, GateLevel: true},
+		{MaxPrintDepth: 2, DrawNodes: true, DrawEdges: true},
+	} {
+		root := &group.Group{}
+		a, b := &wire.Wire{Name: "a"}, &wire.Wire{Name: "b"}
+		outs := sum.HalfSum(root, a, b)
+		n, _ := build([]*wire.Wire{a, b}, outs, []component.Component{root}, cfg)
+		checkLayout(t, n)
+	}
+}
+
+func checkLayout(t *testing.T, n *node) {
+	for i, one := range n.children {
+		if one.x < n.x || one.y < n.y || one.x+one.w > n.x+n.w || one.y+one.h > n.y+n.h {
+			t.Errorf("%s %q is outside %s %q", one.kind, one.label, n.kind, n.label)
+		}
+		for _, other := range n.children[i+1:] {
+			if one.x < other.x+other.w && other.x < one.x+one.w && one.y < other.y+other.h && other.y < one.y+one.h {
+				t.Errorf("%s %q overlaps %s %q", one.kind, one.label, other.kind, other.label)
+			}
+		}
+		checkLayout(t, one)
+	}
+}
+
+func TestAnimation(t *testing.T) {
+	root := &group.Group{}
+	a, b := &wire.Wire{Name: "a"}, &wire.Wire{Name: "b"}
+	outs := sum.HalfSum(root, a, b)
+	cfg := config.Config{MaxPrintDepth: -1, DrawNodes: true, DrawEdges: true}
+	root.Update(true)
+	animation := NewAnimation([]*wire.Wire{a, b}, outs, []component.Component{root}, cfg)
+	animation.Frame("00")
+	a.Bit.SilentSet(true)
+	root.Update(true)
+	animation.Frame("10")
+	if got := len(animation.frames[0].Labels); got != len(animation.canvas.labels) {
+		t.Errorf("first frame labels want %d got %d", len(animation.canvas.labels), got)
+	}
+	want := map[string]bool{"a=1": true, "S(a,b)=1": true}
+	for _, label := range animation.frames[1].Labels {
+		delete(want, label)
+	}
+	if len(want) > 0 {
+		t.Errorf("second frame labels want to contain %v got %v", want, animation.frames[1].Labels)
+	}
+	if animation.frames[0].Strokes == animation.frames[1].Strokes {
+		t.Errorf("second frame strokes did not change")
+	}
+	page, err := animation.HTML()
+	if err != nil {
+		t.Fatalf("HTML got err %v", err)
+	}
+	if want := `"title":"10"`; !strings.Contains(page, want) {
+		t.Errorf("HTML want to contain %q got\n%s", want, page)
+	}
+}
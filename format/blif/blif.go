@@ -0,0 +1,218 @@
+// Package blif exports and imports circuits as Berkeley Logic Interchange Format netlists.
+package blif
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kssilveira/circuit-engine/circuit"
+	"github.com/kssilveira/circuit-engine/component"
+	"github.com/kssilveira/circuit-engine/group"
+	"github.com/kssilveira/circuit-engine/jointwire"
+	"github.com/kssilveira/circuit-engine/netlist"
+	"github.com/kssilveira/circuit-engine/transistor"
+	"github.com/kssilveira/circuit-engine/wire"
+)
+
+const (
+	// Top is the name of the exported model.
+	Top = "top"
+)
+
+var (
+	// covers maps group kinds to the on-set of their single output.
+	covers = map[string][]string{
+		"NOT":  {"0"},
+		"AND":  {"11"},
+		"OR":   {"1-", "-1"},
+		"NAND": {"0-", "-0"},
+		"NOR":  {"00"},
+		"XOR":  {"01", "10"},
+	}
+	// latches maps group kinds to BLIF latch types.
+	latches = map[string]string{
+		"DLATCH": "ah",
+		"DFF":    "re",
+	}
+)
+
+// Export returns the circuit as a flat BLIF model.
+//
+// Each lib/gate group becomes a .names cover, each joint wire becomes an or (or and) cover,
+// and each data latch or flip-flop from lib/latch becomes a .latch.
+// Transistors outside those groups have no BLIF equivalent and return an error.
+func Export(c *circuit.Circuit) (string, error) {
+	e := &exporter{nets: netlist.New(c), driven: map[*wire.Wire]bool{}}
+	e.nameNets()
+	for _, input := range c.Inputs {
+		e.driven[input] = true
+	}
+	if err := e.components(&group.Group{}, c.Components); err != nil {
+		return "", err
+	}
+	var res []string
+	res = append(res, ".model "+Top)
+	res = append(res, ".inputs"+e.list(c.Inputs))
+	res = append(res, ".outputs"+e.list(c.Outputs))
+	for _, w := range e.used {
+		if e.driven[w] {
+			continue
+		}
+		// undriven nets keep their current value
+		e.driven[w] = true
+		res = append(res, ".names "+e.names[w])
+		if w.Bit.SilentGet() {
+			res = append(res, "1")
+		}
+	}
+	for _, output := range c.Outputs {
+		if !e.driven[output] {
+			e.driven[output] = true
+			res = append(res, ".names "+e.names[output])
+			if output.Bit.SilentGet() {
+				res = append(res, "1")
+			}
+		}
+	}
+	res = append(res, e.lines...)
+	res = append(res, ".end")
+	return strings.Join(res, "\n") + "\n", nil
+}
+
+type exporter struct {
+	nets   *netlist.Netlist
+	names  map[*wire.Wire]string
+	driven map[*wire.Wire]bool
+	used   []*wire.Wire
+	seen   map[*wire.Wire]bool
+	lines  []string
+}
+
+// nameNets keeps unique net names and adds the net index to repeated ones.
+func (e *exporter) nameNets() {
+	count := map[string]int{}
+	for _, net := range e.nets.Nets {
+		count[name(net.Name)]++
+	}
+	e.names = map[*wire.Wire]string{}
+	e.seen = map[*wire.Wire]bool{}
+	for i, net := range e.nets.Nets {
+		e.names[net] = name(net.Name)
+		if count[e.names[net]] > 1 {
+			e.names[net] = fmt.Sprintf("%s_%d", e.names[net], i)
+		}
+	}
+}
+
+func (e *exporter) list(ws []*wire.Wire) string {
+	var res strings.Builder
+	for _, w := range ws {
+		res.WriteString(" " + e.names[w])
+	}
+	return res.String()
+}
+
+func (e *exporter) use(ws ...*wire.Wire) {
+	for _, w := range ws {
+		if !e.seen[w] {
+			e.seen[w] = true
+			e.used = append(e.used, w)
+		}
+	}
+}
+
+func (e *exporter) drive(parent *group.Group, w *wire.Wire) error {
+	if e.driven[w] {
+		return fmt.Errorf("net %q in group %q has multiple drivers", w.Name, parent.Name)
+	}
+	e.driven[w] = true
+	return nil
+}
+
+func (e *exporter) components(parent *group.Group, components []component.Component) error {
+	for _, one := range components {
+		switch one := one.(type) {
+		case *group.Group:
+			if err := e.group(one); err != nil {
+				return err
+			}
+		case *transistor.Transistor:
+			return fmt.Errorf("transistor in group %q has no BLIF equivalent, want lib/gate or lib/latch groups", parent.Name)
+		case *jointwire.JointWire:
+			if err := e.drive(parent, one.Res); err != nil {
+				return err
+			}
+			if one.A == one.B {
+				e.use(one.A)
+				e.lines = append(e.lines, ".names"+e.list([]*wire.Wire{one.A, one.Res}), "1 1")
+				continue
+			}
+			e.use(one.A, one.B)
+			e.lines = append(e.lines, ".names"+e.list([]*wire.Wire{one.A, one.B, one.Res}))
+			if one.IsAnd {
+				e.lines = append(e.lines, "11 1")
+			} else {
+				e.lines = append(e.lines, "1- 1", "-1 1")
+			}
+		default:
+			return fmt.Errorf("unsupported component %T", one)
+		}
+	}
+	return nil
+}
+
+func (e *exporter) group(g *group.Group) error {
+	if cover, ok := covers[g.Kind]; ok {
+		out := g.Outs[0]
+		if err := e.drive(g, out); err != nil {
+			return err
+		}
+		e.use(g.Ins...)
+		e.lines = append(e.lines, ".names"+e.list(g.Ins)+e.list(g.Outs))
+		for _, cube := range cover {
+			e.lines = append(e.lines, cube+" 1")
+		}
+		return nil
+	}
+	if typ, ok := latches[g.Kind]; ok {
+		d, en, q, nq := g.Ins[0], g.Ins[1], g.Outs[0], g.Outs[1]
+		if err := e.drive(g, q); err != nil {
+			return err
+		}
+		if err := e.drive(g, nq); err != nil {
+			return err
+		}
+		e.use(d, en)
+		// 3 is the unknown initial value, used before the latch has settled
+		init := "3"
+		if q.Bit.SilentGet() != nq.Bit.SilentGet() {
+			init = bit(q.Bit.SilentGet())
+		}
+		e.lines = append(e.lines,
+			fmt.Sprintf(".latch %s %s %s %s %s", e.names[d], e.names[q], typ, e.names[en], init),
+			".names"+e.list([]*wire.Wire{q, nq}), "0 1")
+		return nil
+	}
+	return e.components(g, g.Components)
+}
+
+// name replaces the characters with a meaning in BLIF.
+func name(s string) string {
+	res := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '#' || r == '=' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, s)
+	if res == "" || res[0] == '.' {
+		return "n" + res
+	}
+	return res
+}
+
+func bit(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
@@ -0,0 +1,84 @@
+package blif
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/kssilveira/circuit-engine/circuit"
+	"github.com/kssilveira/circuit-engine/config"
+	"github.com/kssilveira/circuit-engine/lib"
+)
+
+func TestImport(t *testing.T) {
+	src := `
+# full adder from half adders
+.model full
+.inputs a b cin
+.outputs s cout
+.subckt half a=a b=b s=s1 c=c1
+.subckt half a=s1 b=cin s=s c=c2
+.names c1 c2 cout
+00 0
+.end
+
+.model half
+.inputs a b
+.outputs s c
+.names a b \
+  s
+01 1
+10 1
+.names a b c
+11 1
+.end
+`
+	c := circuit.NewCircuit(config.Config{IsUnitTest: true})
+	if err := Import(c, src, ""); err != nil {
+		t.Fatalf("Import got err %v", err)
+	}
+	want := []string{
+		"000=>00", "001=>10", "010=>10", "011=>01",
+		"100=>10", "101=>01", "110=>01", "111=>11",
+	}
+	if got := c.Simulate(); !slices.Equal(want, got) {
+		t.Errorf("Simulate want %q got %q", want, got)
+	}
+}
+
+func TestImportLatch(t *testing.T) {
+	src := `
+.model dff
+.inputs d clk
+.outputs q
+.latch d q re clk 0
+.end
+`
+	c := circuit.NewCircuit(config.Config{IsUnitTest: true})
+	if err := Import(c, src, ""); err != nil {
+		t.Fatalf("Import got err %v", err)
+	}
+	// q only follows d on the rising edge of clk
+	want := []string{"00=>0", "10=>0", "11=>1", "01=>1", "00=>1", "01=>0"}
+	if got := c.SimulateInputs([]string{"00", "10", "11", "01", "00", "01"}); !slices.Equal(want, got) {
+		t.Errorf("SimulateInputs want %q got %q", want, got)
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	for _, name := range []string{"Not", "And", "Or", "Nand", "Nor", "Xor", "Nand(Nand)", "HalfSum", "Sum", "Sum2", "SumN", "Bus2", "BusBnIOn", "SRLatch", "DLatch", "MSJKLatch", "CounterN", "Register", "RegisterN"} {
+		cfg := config.Config{IsUnitTest: true}
+		c := circuit.NewCircuit(cfg)
+		c.Outs(lib.Example(c, name))
+		src, err := Export(c)
+		if err != nil {
+			t.Fatalf("Export(%q) got err %v", name, err)
+		}
+		imported := circuit.NewCircuit(cfg)
+		if err := Import(imported, src, Top); err != nil {
+			t.Fatalf("Import(Export(%q)) got err %v", name, err)
+		}
+		if want, got := c.Simulate(), imported.Simulate(); !slices.Equal(want, got) {
+			t.Errorf("Simulate(Import(Export(%q))) want %q got %q\n%s", name, want, got, src)
+		}
+	}
+}
@@ -0,0 +1,450 @@
+package blif
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kssilveira/circuit-engine/circuit"
+	"github.com/kssilveira/circuit-engine/group"
+	"github.com/kssilveira/circuit-engine/lib/gate"
+	"github.com/kssilveira/circuit-engine/lib/latch"
+	"github.com/kssilveira/circuit-engine/wire"
+)
+
+// Import adds the given BLIF model to the circuit.
+//
+// The supported subset contains .model, .inputs, .outputs, .clock, .names, .latch, .subckt and .end.
+// Each .names cover becomes lib/gate components, using a single gate when the cover matches one.
+// Each level-sensitive .latch becomes a lib/latch data latch and each edge-triggered one a flip-flop.
+// Latches without a control use a global clock input named "clock".
+// The top model is the given one, or the first one in the file.
+// Its inputs and outputs become the circuit inputs and outputs.
+func Import(c *circuit.Circuit, src, top string) error {
+	models, order, err := parse(src)
+	if err != nil {
+		return err
+	}
+	if len(order) == 0 {
+		return fmt.Errorf("no .model found")
+	}
+	if top == "" {
+		top = order[0]
+	}
+	m, ok := models[top]
+	if !ok {
+		return fmt.Errorf("model %q not found", top)
+	}
+	nets := map[string]*wire.Wire{}
+	for _, input := range m.inputs {
+		nets[input] = c.In(input)
+	}
+	for _, output := range m.outputs {
+		if _, ok := nets[output]; !ok {
+			nets[output] = &wire.Wire{Name: output}
+		}
+		c.Out(nets[output])
+	}
+	b := &builder{models: models, driven: map[*wire.Wire]bool{}, circuit: c}
+	for _, input := range m.inputs {
+		b.driven[nets[input]] = true
+	}
+	return b.build(c.Group(m.name), m, nets, nil)
+}
+
+type model struct {
+	name    string
+	inputs  []string
+	outputs []string
+	names   []names
+	latches []blatch
+	subckts []subckt
+}
+
+// names is a single-output cover, with the input part of each cube and the output value.
+type names struct {
+	ins   []string
+	out   string
+	cubes []string
+	value byte
+	line  int
+}
+
+type blatch struct {
+	in      string
+	out     string
+	typ     string
+	control string
+	init    string
+	line    int
+}
+
+type subckt struct {
+	model       string
+	connections map[string]string
+	line        int
+}
+
+type builder struct {
+	models  map[string]*model
+	driven  map[*wire.Wire]bool
+	circuit *circuit.Circuit
+	clock   *wire.Wire
+}
+
+func (b *builder) build(g *group.Group, m *model, nets map[string]*wire.Wire, stack []string) error {
+	if slices.Contains(stack, m.name) {
+		return fmt.Errorf("recursive instance of model %q", m.name)
+	}
+	stack = append(stack, m.name)
+	net := func(name string) *wire.Wire {
+		if _, ok := nets[name]; !ok {
+			nets[name] = &wire.Wire{Name: name}
+		}
+		return nets[name]
+	}
+	drive := func(line int, name string) (*wire.Wire, error) {
+		w := net(name)
+		if b.driven[w] {
+			return nil, fmt.Errorf("line %d: multiple drivers for %q", line, name)
+		}
+		b.driven[w] = true
+		return w, nil
+	}
+	for _, n := range m.names {
+		var ins []*wire.Wire
+		for _, in := range n.ins {
+			ins = append(ins, net(in))
+		}
+		out, err := drive(n.line, n.out)
+		if err != nil {
+			return err
+		}
+		res := cover(g, ins, n.cubes, n.value)
+		g.JointWire(out, res, res)
+	}
+	for _, l := range m.latches {
+		q, err := drive(l.line, l.out)
+		if err != nil {
+			return err
+		}
+		d := net(l.in)
+		var e *wire.Wire
+		if l.control == "" || l.control == "NIL" {
+			if b.clock == nil {
+				b.clock = b.circuit.In("clock")
+				b.driven[b.clock] = true
+			}
+			e = b.clock
+		} else {
+			e = net(l.control)
+		}
+		var res []*wire.Wire
+		switch l.typ {
+		case "ah":
+			res = latch.DLatchRes(g, q, d, e)
+		case "al":
+			res = latch.DLatchRes(g, q, d, gate.Not(g, e))
+		case "re", "":
+			res = latch.DFlipFlopRes(g, q, d, e)
+		case "fe":
+			res = latch.DFlipFlopRes(g, q, d, gate.Not(g, e))
+		default:
+			return fmt.Errorf("line %d: unsupported latch type %q", l.line, l.typ)
+		}
+		if l.init == "0" || l.init == "1" {
+			res[0].Bit.SilentSet(l.init == "1")
+			res[1].Bit.SilentSet(l.init == "0")
+		}
+	}
+	for _, s := range m.subckts {
+		child, ok := b.models[s.model]
+		if !ok {
+			return fmt.Errorf("line %d: unknown model %q", s.line, s.model)
+		}
+		childNets := map[string]*wire.Wire{}
+		for formal, actual := range s.connections {
+			if !slices.Contains(child.inputs, formal) && !slices.Contains(child.outputs, formal) {
+				return fmt.Errorf("line %d: model %q has no port %q", s.line, child.name, formal)
+			}
+			childNets[formal] = net(actual)
+		}
+		if err := b.build(g.Group(child.name), child, childNets, stack); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cover adds the gates computing the sum of products, using a single gate when possible.
+func cover(g *group.Group, ins []*wire.Wire, cubes []string, value byte) *wire.Wire {
+	if len(ins) <= 2 {
+		table := 0
+		for row := range 1 << len(ins) {
+			if matches(cubes, row, len(ins)) == (value == '1') {
+				table |= 1 << row
+			}
+		}
+		switch {
+		case table == 0:
+			return g.False()
+		case table == 1<<(1<<len(ins))-1:
+			return g.True()
+		}
+		if len(ins) == 1 {
+			// rows are indexed by the input value
+			if table == 0b01 {
+				return gate.Not(g, ins[0])
+			}
+			return ins[0]
+		}
+		// rows are indexed by the first input in the high bit
+		a, b := ins[0], ins[1]
+		switch table {
+		case 0b1000:
+			return gate.And(g, a, b)
+		case 0b1110:
+			return gate.Or(g, a, b)
+		case 0b0111:
+			return gate.Nand(g, a, b)
+		case 0b0001:
+			return gate.Nor(g, a, b)
+		case 0b0110:
+			return gate.Xor(g, a, b)
+		case 0b1001:
+			return gate.Not(g, gate.Xor(g, a, b))
+		}
+	}
+	var products []*wire.Wire
+	for _, cube := range cubes {
+		var literals []*wire.Wire
+		for i, c := range cube {
+			switch c {
+			case '1':
+				literals = append(literals, ins[i])
+			case '0':
+				literals = append(literals, gate.Not(g, ins[i]))
+			}
+		}
+		products = append(products, fold(g, gate.And, literals, g.True))
+	}
+	res := fold(g, gate.Or, products, g.False)
+	if value == '0' {
+		return gate.Not(g, res)
+	}
+	return res
+}
+
+func fold(g *group.Group, fn func(*group.Group, *wire.Wire, *wire.Wire) *wire.Wire, ws []*wire.Wire, empty func() *wire.Wire) *wire.Wire {
+	if len(ws) == 0 {
+		return empty()
+	}
+	res := ws[0]
+	for _, w := range ws[1:] {
+		res = fn(g, res, w)
+	}
+	return res
+}
+
+// matches returns whether the input row, with the first input in the high bit, is in any cube.
+func matches(cubes []string, row, n int) bool {
+	for _, cube := range cubes {
+		ok := true
+		for i, c := range cube {
+			one := row>>(n-1-i)&1 == 1
+			if (c == '1' && !one) || (c == '0' && one) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// ignored contains timing and area commands without a meaning for the simulation.
+	ignored = map[string]bool{
+		".area": true, ".delay": true, ".wire_load_slope": true, ".wire": true,
+		".input_arrival": true, ".output_required": true, ".input_drive": true,
+		".output_load": true, ".max_input_load": true, ".default_input_arrival": true,
+		".default_output_required": true, ".default_input_drive": true,
+		".default_output_load": true, ".default_max_input_load": true,
+	}
+)
+
+// logical is a line after removing comments and joining continuations.
+type logical struct {
+	fields []string
+	line   int
+}
+
+func lines(src string) []logical {
+	var res []logical
+	var fields []string
+	start := 0
+	for i, text := range strings.Split(src, "\n") {
+		if index := strings.Index(text, "#"); index >= 0 {
+			text = text[:index]
+		}
+		text = strings.TrimRight(text, " \t\r")
+		continued := strings.HasSuffix(text, "\\")
+		text = strings.TrimSuffix(text, "\\")
+		if len(fields) == 0 {
+			start = i + 1
+		}
+		fields = append(fields, strings.Fields(text)...)
+		if continued || len(fields) == 0 {
+			continue
+		}
+		res = append(res, logical{fields: fields, line: start})
+		fields = nil
+	}
+	if len(fields) > 0 {
+		res = append(res, logical{fields: fields, line: start})
+	}
+	return res
+}
+
+func parse(src string) (map[string]*model, []string, error) {
+	models := map[string]*model{}
+	var order []string
+	var m *model
+	var current *names
+	skip := false
+	for _, l := range lines(src) {
+		command := l.fields[0]
+		args := l.fields[1:]
+		if !strings.HasPrefix(command, ".") {
+			if skip {
+				continue
+			}
+			if current == nil {
+				return nil, nil, fmt.Errorf("line %d: unexpected %q outside .names", l.line, command)
+			}
+			if err := current.add(l); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		current = nil
+		if command == ".model" {
+			if len(args) != 1 {
+				return nil, nil, fmt.Errorf("line %d: want .model <name>", l.line)
+			}
+			if _, ok := models[args[0]]; ok {
+				return nil, nil, fmt.Errorf("line %d: duplicate model %q", l.line, args[0])
+			}
+			m = &model{name: args[0]}
+			models[m.name] = m
+			order = append(order, m.name)
+			skip = false
+			continue
+		}
+		if m == nil {
+			return nil, nil, fmt.Errorf("line %d: %s outside .model", l.line, command)
+		}
+		if skip && command != ".end" {
+			continue
+		}
+		switch command {
+		case ".inputs":
+			m.inputs = append(m.inputs, args...)
+		case ".outputs":
+			m.outputs = append(m.outputs, args...)
+		case ".clock":
+			for _, arg := range args {
+				if !slices.Contains(m.inputs, arg) {
+					m.inputs = append(m.inputs, arg)
+				}
+			}
+		case ".names":
+			if len(args) == 0 {
+				return nil, nil, fmt.Errorf("line %d: want .names <in>... <out>", l.line)
+			}
+			m.names = append(m.names, names{ins: args[:len(args)-1], out: args[len(args)-1], value: '1', line: l.line})
+			current = &m.names[len(m.names)-1]
+		case ".latch":
+			one, err := parseLatch(args, l.line)
+			if err != nil {
+				return nil, nil, err
+			}
+			m.latches = append(m.latches, one)
+		case ".subckt":
+			if len(args) == 0 {
+				return nil, nil, fmt.Errorf("line %d: want .subckt <model> <formal>=<actual>...", l.line)
+			}
+			one := subckt{model: args[0], connections: map[string]string{}, line: l.line}
+			for _, arg := range args[1:] {
+				formal, actual, ok := strings.Cut(arg, "=")
+				if !ok {
+					return nil, nil, fmt.Errorf("line %d: want <formal>=<actual>, got %q", l.line, arg)
+				}
+				one.connections[formal] = actual
+			}
+			m.subckts = append(m.subckts, one)
+		case ".exdc":
+			// the external don't care network does not change the simulation
+			skip = true
+		case ".end":
+			m = nil
+		default:
+			if !ignored[command] {
+				return nil, nil, fmt.Errorf("line %d: unsupported command %q", l.line, command)
+			}
+		}
+	}
+	return models, order, nil
+}
+
+// add adds a cube to the cover.
+func (n *names) add(l logical) error {
+	cube, value := "", l.fields[0]
+	if len(n.ins) > 0 {
+		if len(l.fields) != 2 {
+			return fmt.Errorf("line %d: want <inputs> <output>, got %q", l.line, strings.Join(l.fields, " "))
+		}
+		cube, value = l.fields[0], l.fields[1]
+	} else if len(l.fields) != 1 {
+		return fmt.Errorf("line %d: want <output>, got %q", l.line, strings.Join(l.fields, " "))
+	}
+	if len(cube) != len(n.ins) || strings.Trim(cube, "01-") != "" {
+		return fmt.Errorf("line %d: want %d characters from \"01-\", got %q", l.line, len(n.ins), cube)
+	}
+	if value != "0" && value != "1" {
+		return fmt.Errorf("line %d: want output 0 or 1, got %q", l.line, value)
+	}
+	if len(n.cubes) > 0 && n.value != value[0] {
+		return fmt.Errorf("line %d: cover of %q mixes on-set and off-set", l.line, n.out)
+	}
+	n.cubes = append(n.cubes, cube)
+	n.value = value[0]
+	return nil
+}
+
+func parseLatch(args []string, line int) (blatch, error) {
+	res := blatch{line: line}
+	switch len(args) {
+	case 2, 3:
+		res.in, res.out = args[0], args[1]
+		if len(args) == 3 {
+			res.init = args[2]
+		}
+	case 4, 5:
+		res.in, res.out, res.typ, res.control = args[0], args[1], args[2], args[3]
+		if len(args) == 5 {
+			res.init = args[4]
+		}
+	default:
+		return res, fmt.Errorf("line %d: want .latch <in> <out> [<type> <control>] [<init>]", line)
+	}
+	if !slices.Contains([]string{"", "fe", "re", "ah", "al"}, res.typ) {
+		return res, fmt.Errorf("line %d: unsupported latch type %q", line, res.typ)
+	}
+	if !slices.Contains([]string{"", "0", "1", "2", "3"}, res.init) {
+		return res, fmt.Errorf("line %d: want latch init 0, 1, 2 or 3, got %q", line, res.init)
+	}
+	return res, nil
+}
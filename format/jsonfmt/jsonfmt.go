@@ -0,0 +1,269 @@
+// Package jsonfmt serializes circuits to and from JSON.
+package jsonfmt
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kssilveira/circuit-engine/behavior"
+	"github.com/kssilveira/circuit-engine/circuit"
+	"github.com/kssilveira/circuit-engine/component"
+	"github.com/kssilveira/circuit-engine/config"
+	"github.com/kssilveira/circuit-engine/group"
+	"github.com/kssilveira/circuit-engine/jointwire"
+	"github.com/kssilveira/circuit-engine/netlist"
+	"github.com/kssilveira/circuit-engine/transistor"
+	"github.com/kssilveira/circuit-engine/wire"
+)
+
+// Circuit contains the JSON representation of a circuit.
+//
+// Wires are stored once in Nets and referenced everywhere else by index.
+// Input validations and behavioral blocks are Go functions, so circuits that have them cannot be serialized.
+type Circuit struct {
+	Inputs     []int       `json:"inputs"`
+	Outputs    []int       `json:"outputs"`
+	Nets       []Net       `json:"nets"`
+	Components []Component `json:"components"`
+}
+
+// Net contains a single wire and its current state.
+type Net struct {
+	Name string `json:"name"`
+	Bit  bool   `json:"bit,omitempty"`
+	Gnd  bool   `json:"gnd,omitempty"`
+}
+
+// Component contains exactly one of its fields.
+type Component struct {
+	Group      *Group      `json:"group,omitempty"`
+	Transistor *Transistor `json:"transistor,omitempty"`
+	JointWire  *JointWire  `json:"jointWire,omitempty"`
+}
+
+// Group contains a group of components.
+type Group struct {
+	Name       string      `json:"name"`
+	Kind       string      `json:"kind,omitempty"`
+	Ins        []int       `json:"ins,omitempty"`
+	Outs       []int       `json:"outs,omitempty"`
+	Components []Component `json:"components"`
+}
+
+// Transistor contains the nets of a transistor.
+type Transistor struct {
+	Base         int `json:"base"`
+	Collector    int `json:"collector"`
+	Emitter      int `json:"emitter"`
+	CollectorOut int `json:"collectorOut"`
+}
+
+// JointWire contains the nets of a joint wire.
+type JointWire struct {
+	Res   int  `json:"res"`
+	A     int  `json:"a"`
+	B     int  `json:"b"`
+	IsAnd bool `json:"isAnd,omitempty"`
+}
+
+// Marshal returns the JSON representation of the circuit.
+func Marshal(c *circuit.Circuit) ([]byte, error) {
+	res, err := FromCircuit(c)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(res, "", " ")
+}
+
+// Unmarshal creates a circuit from its JSON representation.
+func Unmarshal(data []byte, cfg config.Config) (*circuit.Circuit, error) {
+	var res Circuit
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal got err %v", err)
+	}
+	return res.ToCircuit(cfg)
+}
+
+// FromCircuit converts a circuit to its JSON representation.
+func FromCircuit(c *circuit.Circuit) (*Circuit, error) {
+	if len(c.InputValidations) > 0 {
+		return nil, fmt.Errorf("circuit has %d input validations, which are Go functions with no JSON equivalent", len(c.InputValidations))
+	}
+	nets := netlist.New(c)
+	res := &Circuit{}
+	var err error
+	if res.Inputs, err = indexes(nets, c.Inputs...); err != nil {
+		return nil, err
+	}
+	if res.Outputs, err = indexes(nets, c.Outputs...); err != nil {
+		return nil, err
+	}
+	for _, net := range nets.Nets {
+		res.Nets = append(res.Nets, Net{Name: net.Name, Bit: net.Bit.SilentGet(), Gnd: net.Gnd.SilentGet()})
+	}
+	components, err := fromComponents(nets, c.Components)
+	if err != nil {
+		return nil, err
+	}
+	res.Components = components
+	return res, nil
+}
+
+func fromComponents(nets *netlist.Netlist, components []component.Component) ([]Component, error) {
+	var res []Component
+	for _, one := range components {
+		switch one := one.(type) {
+		case *group.Group:
+			components, err := fromComponents(nets, one.Components)
+			if err != nil {
+				return nil, err
+			}
+			ins, err := indexes(nets, one.Ins...)
+			if err != nil {
+				return nil, err
+			}
+			outs, err := indexes(nets, one.Outs...)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, Component{Group: &Group{
+				Name:       one.Name,
+				Kind:       one.Kind,
+				Ins:        ins,
+				Outs:       outs,
+				Components: components,
+			}})
+		case *transistor.Transistor:
+			is, err := indexes(nets, one.Base, one.Collector, one.Emitter, one.CollectorOut)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, Component{Transistor: &Transistor{
+				Base:         is[0],
+				Collector:    is[1],
+				Emitter:      is[2],
+				CollectorOut: is[3],
+			}})
+		case *jointwire.JointWire:
+			is, err := indexes(nets, one.Res, one.A, one.B)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, Component{JointWire: &JointWire{
+				Res:   is[0],
+				A:     is[1],
+				B:     is[2],
+				IsAnd: one.IsAnd,
+			}})
+		case *behavior.Block:
+			return nil, fmt.Errorf("behavioral block %q is a Go function, which has no JSON equivalent", one.Name)
+		default:
+			return nil, fmt.Errorf("unsupported component %T", one)
+		}
+	}
+	return res, nil
+}
+
+func indexes(nets *netlist.Netlist, ws ...*wire.Wire) ([]int, error) {
+	var res []int
+	for _, w := range ws {
+		index, ok := nets.Index(w)
+		if !ok {
+			return nil, fmt.Errorf("wire %q is not in the netlist", w.Name)
+		}
+		res = append(res, index)
+	}
+	return res, nil
+}
+
+// ToCircuit converts the JSON representation back to a circuit.
+func (c *Circuit) ToCircuit(cfg config.Config) (*circuit.Circuit, error) {
+	res := circuit.NewCircuit(cfg)
+	var wires []*wire.Wire
+	for _, net := range c.Nets {
+		one := &wire.Wire{Name: net.Name}
+		one.Bit.SilentSet(net.Bit)
+		one.Gnd.SilentSet(net.Gnd)
+		wires = append(wires, one)
+	}
+	get := func(index int) (*wire.Wire, error) {
+		if index < 0 || index >= len(wires) {
+			return nil, fmt.Errorf("invalid net index %d, want [0, %d)", index, len(wires))
+		}
+		return wires[index], nil
+	}
+	for _, index := range c.Inputs {
+		input, err := get(index)
+		if err != nil {
+			return nil, err
+		}
+		res.Inputs = append(res.Inputs, input)
+	}
+	for _, index := range c.Outputs {
+		output, err := get(index)
+		if err != nil {
+			return nil, err
+		}
+		res.Out(output)
+	}
+	root := &group.Group{}
+	if err := toComponents(root, c.Components, get); err != nil {
+		return nil, err
+	}
+	res.Components = root.Components
+	return res, nil
+}
+
+func toComponents(parent *group.Group, components []Component, get func(int) (*wire.Wire, error)) error {
+	for _, one := range components {
+		switch {
+		case one.Group != nil:
+			group := parent.Group(one.Group.Name)
+			if one.Group.Kind != "" {
+				ins, err := getAll(get, one.Group.Ins...)
+				if err != nil {
+					return err
+				}
+				outs, err := getAll(get, one.Group.Outs...)
+				if err != nil {
+					return err
+				}
+				group.SetKind(one.Group.Kind, ins, outs)
+			}
+			if err := toComponents(group, one.Group.Components, get); err != nil {
+				return err
+			}
+		case one.Transistor != nil:
+			ws, err := getAll(get, one.Transistor.Base, one.Transistor.Collector, one.Transistor.Emitter, one.Transistor.CollectorOut)
+			if err != nil {
+				return err
+			}
+			parent.Transistor(ws[0], ws[1], ws[2], ws[3])
+		case one.JointWire != nil:
+			ws, err := getAll(get, one.JointWire.Res, one.JointWire.A, one.JointWire.B)
+			if err != nil {
+				return err
+			}
+			if one.JointWire.IsAnd {
+				parent.JointWireIsAnd(ws[0], ws[1], ws[2])
+			} else {
+				parent.JointWire(ws[0], ws[1], ws[2])
+			}
+		default:
+			return fmt.Errorf("empty component in group %q", parent.Name)
+		}
+	}
+	return nil
+}
+
+func getAll(get func(int) (*wire.Wire, error), indexes ...int) ([]*wire.Wire, error) {
+	var res []*wire.Wire
+	for _, index := range indexes {
+		one, err := get(index)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, one)
+	}
+	return res, nil
+}
@@ -0,0 +1,93 @@
+package jsonfmt
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kssilveira/circuit-engine/circuit"
+	"github.com/kssilveira/circuit-engine/config"
+	"github.com/kssilveira/circuit-engine/lib"
+	"github.com/kssilveira/circuit-engine/netlist"
+	"github.com/kssilveira/circuit-engine/wire"
+)
+
+func TestRoundTrip(t *testing.T) {
+	for _, name := range lib.ExampleNames() {
+		cfg := config.Config{IsUnitTest: true}
+		c := circuit.NewCircuit(cfg)
+		c.Outs(lib.Example(c, name))
+		data, err := Marshal(c)
+		if name == "ROM" || len(c.InputValidations) > 0 {
+			// covered by TestMarshalErrors
+			if err == nil {
+				t.Errorf("Marshal(%q) got no err", name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Marshal(%q) got err %v", name, err)
+		}
+		loaded, err := Unmarshal(data, cfg)
+		if err != nil {
+			t.Fatalf("Unmarshal(%q) got err %v", name, err)
+		}
+		again, err := Marshal(loaded)
+		if err != nil {
+			t.Fatalf("Marshal(Unmarshal(%q)) got err %v", name, err)
+		}
+		if string(again) != string(data) {
+			t.Errorf("Marshal(Unmarshal(%q)) differs from Marshal(%q)", name, name)
+		}
+		if want, got := c.Description(), loaded.Description(); want != got {
+			t.Errorf("Description(%q) want %q got %q", name, want, got)
+		}
+		if want, got := c.Simulate(), loaded.Simulate(); !slices.Equal(want, got) {
+			t.Errorf("Simulate(%q) want %q got %q", name, want, got)
+		}
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	for _, in := range []struct {
+		name string
+		want string
+	}{
+		{name: "ROM", want: "behavioral block"},
+		{name: "AluWithBus", want: "input validations"},
+	} {
+		c := circuit.NewCircuit(config.Config{IsUnitTest: true})
+		c.Outs(lib.Example(c, in.name))
+		if _, err := Marshal(c); err == nil || !strings.Contains(err.Error(), in.want) {
+			t.Errorf("Marshal(%q) want err containing %q got %v", in.name, in.want, err)
+		}
+	}
+}
+
+func TestIndexesMissingWire(t *testing.T) {
+	c := circuit.NewCircuit(config.Config{IsUnitTest: true})
+	c.Outs(lib.Example(c, "And"))
+	nets := netlist.New(c)
+	if _, err := indexes(nets, &wire.Wire{Name: "missing"}); err == nil || !strings.Contains(err.Error(), `"missing"`) {
+		t.Errorf("indexes(missing) want err got %v", err)
+	}
+}
+
+func TestRoundTripState(t *testing.T) {
+	cfg := config.Config{IsUnitTest: true}
+	c := circuit.NewCircuit(cfg)
+	c.Outs(lib.Example(c, "CounterN"))
+	c.SimulateInputs([]string{"0", "1", "0", "1", "0"})
+	data, err := Marshal(c)
+	if err != nil {
+		t.Fatalf("Marshal got err %v", err)
+	}
+	loaded, err := Unmarshal(data, cfg)
+	if err != nil {
+		t.Fatalf("Unmarshal got err %v", err)
+	}
+	inputs := []string{"1", "0", "1", "0", "1", "0"}
+	if want, got := c.SimulateInputs(inputs), loaded.SimulateInputs(inputs); !slices.Equal(want, got) {
+		t.Errorf("SimulateInputs want %q got %q", want, got)
+	}
+}
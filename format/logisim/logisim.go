@@ -0,0 +1,663 @@
+// Package logisim imports Logisim .circ files.
+package logisim
+
+import (
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kssilveira/circuit-engine/circuit"
+	"github.com/kssilveira/circuit-engine/group"
+	"github.com/kssilveira/circuit-engine/lib/gate"
+	"github.com/kssilveira/circuit-engine/lib/latch"
+	"github.com/kssilveira/circuit-engine/wire"
+)
+
+// Import adds the given Logisim circuit to the circuit.
+//
+// The supported components are pins, tunnels, splitters, constants, power, ground and clocks from Wiring,
+// the NOT, Buffer, AND, OR, NAND, NOR, XOR and XNOR gates, the D flip-flop from Memory, and subcircuits
+// with the default or a custom appearance.
+// Gates become lib/gate components, D flip-flops become lib/latch flip-flops or latches depending on
+// their trigger, and each subcircuit becomes a nested group.
+// The top circuit is the given one, or the main circuit of the project.
+// Its pins, sorted top to bottom and then left to right, become the circuit inputs and outputs,
+// with the bit index appended to the label of pins wider than one bit.
+func Import(c *circuit.Circuit, src, top string) error {
+	var p project
+	if err := xml.Unmarshal([]byte(src), &p); err != nil {
+		return fmt.Errorf("xml.Unmarshal got err %v", err)
+	}
+	b := &builder{
+		circuit:  c,
+		circuits: map[string]*xcircuit{},
+		libs:     map[string]string{},
+		inputs:   5,
+		driven:   map[*wire.Wire]bool{},
+		clocks:   map[string]*wire.Wire{},
+	}
+	if !strings.HasPrefix(p.Source, "2.") {
+		// Logisim-evolution gates default to 2 inputs instead of 5
+		b.inputs = 2
+	}
+	for _, l := range p.Libs {
+		b.libs[l.Name] = l.Desc
+	}
+	for i := range p.Circuits {
+		b.circuits[p.Circuits[i].Name] = &p.Circuits[i]
+	}
+	if top == "" {
+		top = p.Main.Name
+	}
+	if top == "" && len(p.Circuits) > 0 {
+		top = p.Circuits[0].Name
+	}
+	x, ok := b.circuits[top]
+	if !ok {
+		return fmt.Errorf("circuit %q not found", top)
+	}
+	pins, err := b.pins(x)
+	if err != nil {
+		return err
+	}
+	ins := map[point][]*wire.Wire{}
+	for _, pin := range pins {
+		if pin.output {
+			continue
+		}
+		for _, name := range pin.names() {
+			in := c.In(name)
+			b.driven[in] = true
+			ins[pin.loc] = append(ins[pin.loc], in)
+		}
+	}
+	outs, err := b.build(c.Group(x.Name), x, ins, nil)
+	if err != nil {
+		return err
+	}
+	for _, pin := range pins {
+		if pin.output {
+			c.Outs(outs[pin.loc])
+		}
+	}
+	return nil
+}
+
+type project struct {
+	Source string `xml:"source,attr"`
+	Libs   []xlib `xml:"lib"`
+	Main   struct {
+		Name string `xml:"name,attr"`
+	} `xml:"main"`
+	Circuits []xcircuit `xml:"circuit"`
+}
+
+type xlib struct {
+	Name string `xml:"name,attr"`
+	Desc string `xml:"desc,attr"`
+}
+
+type xcircuit struct {
+	Name   string  `xml:"name,attr"`
+	Wires  []xwire `xml:"wire"`
+	Comps  []comp  `xml:"comp"`
+	Appear *appear `xml:"appear"`
+}
+
+type xwire struct {
+	From string `xml:"from,attr"`
+	To   string `xml:"to,attr"`
+}
+
+type comp struct {
+	Lib   string  `xml:"lib,attr"`
+	Loc   string  `xml:"loc,attr"`
+	Name  string  `xml:"name,attr"`
+	Attrs []xattr `xml:"a"`
+}
+
+type xattr struct {
+	Name string `xml:"name,attr"`
+	Val  string `xml:"val,attr"`
+	Text string `xml:",chardata"`
+}
+
+type appear struct {
+	Ports  []box `xml:"circ-port"`
+	Anchor *box  `xml:"circ-anchor"`
+}
+
+// box is a port or anchor of a custom appearance.
+type box struct {
+	Pin    string `xml:"pin,attr"`
+	Facing string `xml:"facing,attr"`
+	X      int    `xml:"x,attr"`
+	Y      int    `xml:"y,attr"`
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+}
+
+func (b box) center() point {
+	return point{b.X + b.Width/2, b.Y + b.Height/2}
+}
+
+func (c comp) attr(name, value string) string {
+	for _, a := range c.Attrs {
+		if a.Name == name {
+			if a.Val == "" {
+				return a.Text
+			}
+			return a.Val
+		}
+	}
+	return value
+}
+
+func (c comp) int(name string, value int) (int, error) {
+	s := c.attr(name, "")
+	if s == "" {
+		return value, nil
+	}
+	res, err := strconv.ParseInt(s, 0, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s at %s: invalid %s %q", c.Name, c.Loc, name, s)
+	}
+	return int(res), nil
+}
+
+func parsePoint(s string) (point, error) {
+	var res point
+	if _, err := fmt.Sscanf(strings.TrimSpace(s), "(%d,%d)", &res.x, &res.y); err != nil {
+		if _, err := fmt.Sscanf(strings.TrimSpace(s), "%d,%d", &res.x, &res.y); err != nil {
+			return res, fmt.Errorf("invalid location %q", s)
+		}
+	}
+	return res, nil
+}
+
+// pin is a pin of a circuit, which is a port of its subcircuit instances.
+type pin struct {
+	loc    point
+	label  string
+	width  int
+	output bool
+	facing string
+}
+
+func (p pin) names() []string {
+	label := p.label
+	if label == "" {
+		label = fmt.Sprintf("pin(%d,%d)", p.loc.x, p.loc.y)
+	}
+	if p.width == 1 {
+		return []string{label}
+	}
+	var res []string
+	for i := range p.width {
+		res = append(res, fmt.Sprintf("%s%d", label, i))
+	}
+	return res
+}
+
+// pins returns the pins of the circuit, sorted top to bottom and then left to right.
+func (b *builder) pins(x *xcircuit) ([]pin, error) {
+	var res []pin
+	for _, c := range x.Comps {
+		if c.Name != "Pin" || b.libs[c.Lib] != "#Wiring" {
+			continue
+		}
+		loc, err := parsePoint(c.Loc)
+		if err != nil {
+			return nil, fmt.Errorf("circuit %q: %v", x.Name, err)
+		}
+		width, err := c.int("width", 1)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, pin{
+			loc:    loc,
+			label:  c.attr("label", ""),
+			width:  width,
+			output: c.attr("output", "false") == "true",
+			facing: c.attr("facing", "east"),
+		})
+	}
+	slices.SortStableFunc(res, func(a, b pin) int {
+		return cmp.Or(cmp.Compare(a.loc.y, b.loc.y), cmp.Compare(a.loc.x, b.loc.x))
+	})
+	return res, nil
+}
+
+type builder struct {
+	circuit  *circuit.Circuit
+	circuits map[string]*xcircuit
+	libs     map[string]string
+	// inputs is the default number of gate inputs
+	inputs int
+	driven map[*wire.Wire]bool
+	clocks map[string]*wire.Wire
+}
+
+// port is a connection point of a component.
+type port struct {
+	loc   point
+	width int
+}
+
+// part is a component with its ports, the outputs after the inputs.
+type part struct {
+	comp  comp
+	kind  string
+	loc   point
+	ins   []port
+	outs  []port
+	extra []port
+	// ends contains the end of each bit for splitters
+	ends []int
+}
+
+func (b *builder) build(g *group.Group, x *xcircuit, ins map[point][]*wire.Wire, stack []string) (map[point][]*wire.Wire, error) {
+	if slices.Contains(stack, x.Name) {
+		return nil, fmt.Errorf("recursive instance of circuit %q", x.Name)
+	}
+	stack = append(stack, x.Name)
+	var parts []part
+	for _, c := range x.Comps {
+		one, err := b.part(c)
+		if err != nil {
+			return nil, fmt.Errorf("circuit %q: %v", x.Name, err)
+		}
+		if one.kind != "" {
+			parts = append(parts, one)
+		}
+	}
+	n, err := newNets(x, parts)
+	if err != nil {
+		return nil, fmt.Errorf("circuit %q: %v", x.Name, err)
+	}
+	// input pins use the wires given by the parent
+	for _, one := range parts {
+		if one.kind != "Pin" || one.comp.attr("output", "false") == "true" {
+			continue
+		}
+		for i, w := range ins[one.loc] {
+			if err := n.assign(one.loc, i, w); err != nil {
+				return nil, fmt.Errorf("circuit %q: %v", x.Name, err)
+			}
+		}
+	}
+	outs := map[point][]*wire.Wire{}
+	for _, one := range parts {
+		res, err := b.apply(g, n, one, stack)
+		if err != nil {
+			return nil, fmt.Errorf("circuit %q: %s at (%d,%d): %v", x.Name, one.comp.Name, one.loc.x, one.loc.y, err)
+		}
+		if one.kind == "Pin" {
+			outs[one.loc] = res
+			continue
+		}
+		for i, out := range one.outs {
+			if err := b.drive(g, n, out, res[i*out.width:(i+1)*out.width]); err != nil {
+				return nil, fmt.Errorf("circuit %q: %s at (%d,%d): %v", x.Name, one.comp.Name, one.loc.x, one.loc.y, err)
+			}
+		}
+	}
+	return outs, nil
+}
+
+// drive connects the component output wires to the nets of the output port.
+func (b *builder) drive(g *group.Group, n *nets, out port, res []*wire.Wire) error {
+	for i, w := range n.bits(out.loc) {
+		if res[i] == nil {
+			continue
+		}
+		if b.driven[w] {
+			return fmt.Errorf("multiple drivers for net %q", w.Name)
+		}
+		b.driven[w] = true
+		if w.Name == "" {
+			w.Name = res[i].Name
+		}
+		g.JointWire(w, res[i], res[i])
+	}
+	return nil
+}
+
+// part returns the component with its ports, or an empty kind for components without effect.
+func (b *builder) part(c comp) (part, error) {
+	loc, err := parsePoint(c.Loc)
+	if err != nil {
+		return part{}, err
+	}
+	res := part{comp: c, loc: loc}
+	width, err := c.int("width", 1)
+	if err != nil {
+		return res, err
+	}
+	facing := c.attr("facing", "east")
+	if c.Lib == "" {
+		return b.subcircuit(c, loc, facing)
+	}
+	lib := b.libs[c.Lib]
+	switch {
+	case lib == "#Wiring" && (c.Name == "Pin" || c.Name == "Tunnel" || c.Name == "Clock"):
+		res.kind = c.Name
+		res.extra = []port{{loc, width}}
+		if c.Name == "Clock" {
+			res.outs = []port{{loc, 1}}
+			res.extra = nil
+		}
+	case lib == "#Wiring" && (c.Name == "Constant" || c.Name == "Power" || c.Name == "Ground"):
+		res.kind = c.Name
+		res.outs = []port{{loc, width}}
+	case lib == "#Wiring" && c.Name == "Splitter":
+		res.kind = c.Name
+		fanout, err := c.int("fanout", 2)
+		if err != nil {
+			return res, err
+		}
+		incoming, err := c.int("incoming", 2)
+		if err != nil {
+			return res, err
+		}
+		ends, err := splitterEnds(c, fanout, incoming)
+		if err != nil {
+			return res, err
+		}
+		res.ends = ends
+		res.extra = []port{{loc, incoming}}
+		for i := range fanout {
+			count := 0
+			for _, end := range ends {
+				if end == i {
+					count++
+				}
+			}
+			res.extra = append(res.extra, port{loc.add(splitterEnd(facing, c.attr("appear", "left"), fanout, i)), count})
+		}
+	case lib == "#Wiring" && (c.Name == "Probe" || c.Name == "Pull Resistor"):
+		// probes do not change the circuit and floating nets are already false
+		if c.Name == "Pull Resistor" && c.attr("pull", "0") == "1" {
+			return res, fmt.Errorf("pull up resistors are not supported")
+		}
+	case lib == "#Gates" && (c.Name == "NOT Gate" || c.Name == "Buffer"):
+		res.kind = c.Name
+		axis := 20
+		if c.Name == "NOT Gate" {
+			axis, err = c.int("size", 30)
+			if err != nil {
+				return res, err
+			}
+		}
+		res.ins = []port{{loc.add(rotate(point{-axis, 0}, facing)), width}}
+		res.outs = []port{{loc, width}}
+	case lib == "#Gates" && slices.Contains([]string{"AND Gate", "OR Gate", "NAND Gate", "NOR Gate", "XOR Gate", "XNOR Gate"}, c.Name):
+		res.kind = c.Name
+		size, err := c.int("size", 50)
+		if err != nil {
+			return res, err
+		}
+		inputs, err := c.int("inputs", b.inputs)
+		if err != nil {
+			return res, err
+		}
+		axis := size + map[string]int{"NAND Gate": 10, "NOR Gate": 10, "XOR Gate": 10, "XNOR Gate": 20}[c.Name]
+		for i := range inputs {
+			negated := c.attr(fmt.Sprintf("negate%d", i), "false") == "true"
+			res.ins = append(res.ins, port{loc.add(gateInput(facing, size, inputs, i, axis, negated)), width})
+		}
+		res.outs = []port{{loc, width}}
+	case lib == "#Memory" && c.Name == "D Flip-Flop":
+		res.kind = c.Name
+		res.ins = []port{{loc.add(point{-40, 0}), 1}, {loc.add(point{-40, 20}), 1}}
+		res.outs = []port{{loc, 1}, {loc.add(point{0, 20}), 1}}
+		// reset, preset and enable
+		res.extra = []port{{loc.add(point{-10, 30}), 1}, {loc.add(point{-30, 30}), 1}, {loc.add(point{-20, 30}), 1}}
+	case lib == "#Base":
+		// text and other annotations
+	default:
+		return res, fmt.Errorf("unsupported component %q from library %q at %s", c.Name, lib, c.Loc)
+	}
+	return res, nil
+}
+
+func splitterEnds(c comp, fanout, incoming int) ([]int, error) {
+	res := splitterBits(fanout, incoming)
+	for i := range res {
+		value := c.attr(fmt.Sprintf("bit%d", i), "")
+		switch value {
+		case "":
+		case "none":
+			res[i] = -1
+		default:
+			end, err := strconv.Atoi(value)
+			if err != nil || end < 0 || end >= fanout {
+				return nil, fmt.Errorf("Splitter at %s: invalid bit%d %q", c.Loc, i, value)
+			}
+			res[i] = end
+		}
+	}
+	return res, nil
+}
+
+// subcircuit returns the instance ports, the inputs first, in the order of the subcircuit pins.
+func (b *builder) subcircuit(c comp, loc point, facing string) (part, error) {
+	res := part{comp: c, loc: loc, kind: "Subcircuit"}
+	x, ok := b.circuits[c.Name]
+	if !ok {
+		return res, fmt.Errorf("unknown circuit %q at %s", c.Name, c.Loc)
+	}
+	pins, err := b.pins(x)
+	if err != nil {
+		return res, err
+	}
+	offsets := map[point]point{}
+	from := "east"
+	if x.Appear != nil && x.Appear.Anchor != nil {
+		anchor := x.Appear.Anchor.center()
+		if x.Appear.Anchor.Facing != "" {
+			from = x.Appear.Anchor.Facing
+		}
+		for _, one := range x.Appear.Ports {
+			at, err := parsePoint(one.Pin)
+			if err != nil {
+				return res, err
+			}
+			center := one.center()
+			offsets[at] = point{center.x - anchor.x, center.y - anchor.y}
+		}
+	} else {
+		var all []appearancePin
+		for _, pin := range pins {
+			all = append(all, appearancePin{loc: pin.loc, facing: pin.facing})
+		}
+		offsets = defaultAppearance(all)
+	}
+	for _, output := range []bool{false, true} {
+		for _, pin := range pins {
+			if pin.output != output {
+				continue
+			}
+			offset, ok := offsets[pin.loc]
+			if !ok {
+				return res, fmt.Errorf("circuit %q appearance has no port for pin at (%d,%d)", x.Name, pin.loc.x, pin.loc.y)
+			}
+			one := port{loc.add(rotateFrom(offset, from, facing)), pin.width}
+			if output {
+				res.outs = append(res.outs, one)
+			} else {
+				res.ins = append(res.ins, one)
+			}
+		}
+	}
+	return res, nil
+}
+
+// apply adds the components and returns the output wires, one per bit of each output port.
+func (b *builder) apply(g *group.Group, n *nets, one part, stack []string) ([]*wire.Wire, error) {
+	c := one.comp
+	switch one.kind {
+	case "Pin":
+		if c.attr("output", "false") != "true" {
+			return nil, nil
+		}
+		p := pin{loc: one.loc, label: c.attr("label", ""), width: one.extra[0].width}
+		var res []*wire.Wire
+		for i, w := range n.bits(one.loc) {
+			out := &wire.Wire{Name: p.names()[i]}
+			g.JointWire(out, w, w)
+			res = append(res, out)
+		}
+		return res, nil
+	case "Tunnel", "Splitter":
+		return nil, nil
+	case "Clock":
+		name := c.attr("label", "clock")
+		if _, ok := b.clocks[name]; !ok {
+			b.clocks[name] = b.circuit.In(name)
+			b.driven[b.clocks[name]] = true
+		}
+		return []*wire.Wire{b.clocks[name]}, nil
+	case "Constant", "Power", "Ground":
+		value, err := c.int("value", 1)
+		if err != nil {
+			return nil, err
+		}
+		var res []*wire.Wire
+		for i := range one.outs[0].width {
+			if (c.Name == "Constant" && value>>i&1 == 1) || c.Name == "Power" {
+				res = append(res, g.True())
+			} else {
+				res = append(res, g.False())
+			}
+		}
+		return res, nil
+	case "D Flip-Flop":
+		for _, extra := range one.extra {
+			if n.connected(extra.loc) {
+				return nil, fmt.Errorf("reset, preset and enable are not supported")
+			}
+		}
+		d, e := n.input(g, one.ins[0])[0], n.input(g, one.ins[1])[0]
+		var res []*wire.Wire
+		switch trigger := c.attr("trigger", "rising"); trigger {
+		case "rising":
+			res = latch.DFlipFlop(g, d, e)
+		case "falling":
+			res = latch.DFlipFlop(g, d, gate.Not(g, e))
+		case "high":
+			res = latch.DLatch(g, d, e)
+		case "low":
+			res = latch.DLatch(g, d, gate.Not(g, e))
+		default:
+			return nil, fmt.Errorf("unsupported trigger %q", trigger)
+		}
+		// Logisim flip-flops start at 0
+		res[1].Bit.SilentSet(true)
+		return res, nil
+	case "Subcircuit":
+		child := b.circuits[c.Name]
+		pins, err := b.pins(child)
+		if err != nil {
+			return nil, err
+		}
+		ins := map[point][]*wire.Wire{}
+		var inputs []pin
+		for _, pin := range pins {
+			if !pin.output {
+				inputs = append(inputs, pin)
+			}
+		}
+		for i, pin := range inputs {
+			ins[pin.loc] = n.bits(one.ins[i].loc)
+		}
+		outs, err := b.build(g.Group(child.Name), child, ins, stack)
+		if err != nil {
+			return nil, err
+		}
+		var res []*wire.Wire
+		for _, pin := range pins {
+			if pin.output {
+				res = append(res, outs[pin.loc]...)
+			}
+		}
+		return res, nil
+	}
+	// gates work bit by bit and ignore unconnected inputs
+	width := one.outs[0].width
+	var res []*wire.Wire
+	for bit := range width {
+		var args []*wire.Wire
+		for i, in := range one.ins {
+			if !n.connected(in.loc) {
+				continue
+			}
+			arg := n.bits(in.loc)[bit]
+			if c.attr(fmt.Sprintf("negate%d", i), "false") == "true" {
+				arg = gate.Not(g, arg)
+			}
+			args = append(args, arg)
+		}
+		if len(args) == 0 {
+			// a gate without inputs does not drive its output
+			res = append(res, nil)
+			continue
+		}
+		res = append(res, apply(g, one.kind, c.attr("xor", "1"), args))
+	}
+	return res, nil
+}
+
+// apply adds the gates computing the Logisim gate over the arguments.
+func apply(g *group.Group, kind, xor string, args []*wire.Wire) *wire.Wire {
+	fold := func(fn func(*group.Group, *wire.Wire, *wire.Wire) *wire.Wire) *wire.Wire {
+		res := args[0]
+		for _, arg := range args[1:] {
+			res = fn(g, res, arg)
+		}
+		return res
+	}
+	switch kind {
+	case "NOT Gate":
+		return gate.Not(g, args[0])
+	case "AND Gate":
+		return fold(gate.And)
+	case "OR Gate":
+		return fold(gate.Or)
+	case "NAND Gate":
+		if len(args) == 2 {
+			return gate.Nand(g, args[0], args[1])
+		}
+		return gate.Not(g, fold(gate.And))
+	case "NOR Gate":
+		if len(args) == 2 {
+			return gate.Nor(g, args[0], args[1])
+		}
+		return gate.Not(g, fold(gate.Or))
+	case "XOR Gate", "XNOR Gate":
+		res := fold(gate.Xor)
+		if xor != "odd" && len(args) > 2 {
+			// exactly one input is true: odd parity and no two inputs are true
+			var pairs []*wire.Wire
+			for i := range args {
+				for j := i + 1; j < len(args); j++ {
+					pairs = append(pairs, gate.And(g, args[i], args[j]))
+				}
+			}
+			two := pairs[0]
+			for _, pair := range pairs[1:] {
+				two = gate.Or(g, two, pair)
+			}
+			res = gate.And(g, res, gate.Not(g, two))
+		}
+		if kind == "XNOR Gate" {
+			return gate.Not(g, res)
+		}
+		return res
+	}
+	// buffer
+	return args[0]
+}
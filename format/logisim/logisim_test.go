@@ -0,0 +1,142 @@
+package logisim
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/kssilveira/circuit-engine/circuit"
+	"github.com/kssilveira/circuit-engine/config"
+)
+
+func TestImport(t *testing.T) {
+	src := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<project source="2.7.1" version="1.0">
+  <lib desc="#Wiring" name="0"/>
+  <lib desc="#Gates" name="1"/>
+  <lib desc="#Memory" name="4"/>
+  <lib desc="#Base" name="6"/>
+  <main name="main"/>
+  <circuit name="main">
+    <a name="circuit" val="main"/>
+    <wire from="(40,100)" to="(60,100)"/>
+    <wire from="(80,70)" to="(170,70)"/>
+    <wire from="(80,80)" to="(170,80)"/>
+    <wire from="(200,70)" to="(270,70)"/>
+    <wire from="(80,90)" to="(250,90)"/>
+    <wire from="(250,90)" to="(250,80)"/>
+    <wire from="(250,80)" to="(270,80)"/>
+    <wire from="(200,80)" to="(220,80)"/>
+    <wire from="(220,80)" to="(220,130)"/>
+    <wire from="(220,130)" to="(350,130)"/>
+    <wire from="(300,80)" to="(320,80)"/>
+    <wire from="(320,80)" to="(320,170)"/>
+    <wire from="(320,170)" to="(350,170)"/>
+    <wire from="(300,70)" to="(500,70)"/>
+    <wire from="(400,150)" to="(450,150)"/>
+    <wire from="(450,150)" to="(500,150)"/>
+    <wire from="(440,300)" to="(460,300)"/>
+    <wire from="(40,320)" to="(460,320)"/>
+    <wire from="(500,300)" to="(600,300)"/>
+    <comp lib="0" loc="(40,100)" name="Pin">
+      <a name="width" val="3"/>
+      <a name="tristate" val="false"/>
+      <a name="label" val="in"/>
+    </comp>
+    <comp lib="0" loc="(60,100)" name="Splitter">
+      <a name="fanout" val="3"/>
+      <a name="incoming" val="3"/>
+    </comp>
+    <comp loc="(200,70)" name="half"/>
+    <comp loc="(300,70)" name="half"/>
+    <comp lib="1" loc="(400,150)" name="OR Gate">
+      <a name="inputs" val="2"/>
+    </comp>
+    <comp lib="0" loc="(500,70)" name="Pin">
+      <a name="facing" val="west"/>
+      <a name="output" val="true"/>
+      <a name="label" val="sum"/>
+    </comp>
+    <comp lib="0" loc="(500,150)" name="Pin">
+      <a name="facing" val="west"/>
+      <a name="output" val="true"/>
+      <a name="label" val="cout"/>
+    </comp>
+    <comp lib="0" loc="(450,150)" name="Tunnel">
+      <a name="facing" val="north"/>
+      <a name="label" val="co"/>
+    </comp>
+    <comp lib="0" loc="(440,300)" name="Tunnel">
+      <a name="label" val="co"/>
+    </comp>
+    <comp lib="0" loc="(40,320)" name="Pin">
+      <a name="label" val="clk"/>
+    </comp>
+    <comp lib="4" loc="(500,300)" name="D Flip-Flop"/>
+    <comp lib="0" loc="(600,300)" name="Pin">
+      <a name="facing" val="west"/>
+      <a name="output" val="true"/>
+      <a name="label" val="q"/>
+    </comp>
+    <comp lib="6" loc="(300,400)" name="Text">
+      <a name="text" val="full adder"/>
+    </comp>
+  </circuit>
+  <circuit name="half">
+    <a name="circuit" val="half"/>
+    <wire from="(40,80)" to="(100,80)"/>
+    <wire from="(100,80)" to="(150,80)"/>
+    <wire from="(100,80)" to="(100,180)"/>
+    <wire from="(100,180)" to="(140,180)"/>
+    <wire from="(40,120)" to="(80,120)"/>
+    <wire from="(80,120)" to="(150,120)"/>
+    <wire from="(80,120)" to="(80,220)"/>
+    <wire from="(80,220)" to="(140,220)"/>
+    <wire from="(200,200)" to="(300,200)"/>
+    <wire from="(200,100)" to="(250,100)"/>
+    <wire from="(260,300)" to="(300,300)"/>
+    <comp lib="0" loc="(40,80)" name="Pin">
+      <a name="label" val="a"/>
+    </comp>
+    <comp lib="0" loc="(40,120)" name="Pin">
+      <a name="label" val="b"/>
+    </comp>
+    <comp lib="1" loc="(200,100)" name="AND Gate">
+      <a name="inputs" val="2"/>
+    </comp>
+    <comp lib="1" loc="(200,200)" name="XOR Gate">
+      <a name="inputs" val="2"/>
+    </comp>
+    <comp lib="0" loc="(250,100)" name="Tunnel">
+      <a name="facing" val="west"/>
+      <a name="label" val="carry"/>
+    </comp>
+    <comp lib="0" loc="(260,300)" name="Tunnel">
+      <a name="label" val="carry"/>
+    </comp>
+    <comp lib="0" loc="(300,200)" name="Pin">
+      <a name="facing" val="west"/>
+      <a name="output" val="true"/>
+      <a name="label" val="s"/>
+    </comp>
+    <comp lib="0" loc="(300,300)" name="Pin">
+      <a name="facing" val="west"/>
+      <a name="output" val="true"/>
+      <a name="label" val="c"/>
+    </comp>
+  </circuit>
+</project>
+`
+	c := circuit.NewCircuit(config.Config{IsUnitTest: true})
+	if err := Import(c, src, ""); err != nil {
+		t.Fatalf("Import got err %v", err)
+	}
+	if want, got := "in0 in1 in2 clk => sum cout q", c.Description(); want != got {
+		t.Errorf("Description want %q got %q", want, got)
+	}
+	// q follows cout on the rising edge of clk
+	vectors := []string{"0000", "1100", "1101", "0001", "1000", "0110", "1111", "0010"}
+	want := []string{"0000=>000", "1100=>010", "1101=>011", "0001=>001", "1000=>101", "0110=>011", "1111=>111", "0010=>101"}
+	if got := c.SimulateInputs(vectors); !slices.Equal(want, got) {
+		t.Errorf("SimulateInputs want %q got %q", want, got)
+	}
+}
@@ -0,0 +1,203 @@
+package logisim
+
+import (
+	"fmt"
+
+	"github.com/kssilveira/circuit-engine/group"
+	"github.com/kssilveira/circuit-engine/wire"
+)
+
+// nets connects the locations of a circuit, and then the bits of those locations.
+//
+// Wires and tunnels connect locations, which carry the same number of bits,
+// and splitters connect bits of different locations.
+type nets struct {
+	index  map[point]int
+	parent []int
+	// count is the number of wire ends and ports at each location
+	count []int
+	width []int
+	// first is the first bit of each location
+	first     []int
+	bitParent []int
+	wires     map[int]*wire.Wire
+	names     map[int]string
+}
+
+func newNets(x *xcircuit, parts []part) (*nets, error) {
+	n := &nets{index: map[point]int{}, wires: map[int]*wire.Wire{}, names: map[int]string{}}
+	for _, w := range x.Wires {
+		from, err := parsePoint(w.From)
+		if err != nil {
+			return nil, err
+		}
+		to, err := parsePoint(w.To)
+		if err != nil {
+			return nil, err
+		}
+		n.union(n.add(from), n.add(to))
+	}
+	tunnels := map[string]int{}
+	for _, one := range parts {
+		for _, p := range one.ports() {
+			n.add(p.loc)
+		}
+		if one.kind == "Tunnel" {
+			label := one.comp.attr("label", "")
+			if first, ok := tunnels[label]; ok {
+				n.union(first, n.index[one.loc])
+			} else {
+				tunnels[label] = n.index[one.loc]
+			}
+		}
+	}
+	for range n.parent {
+		n.width = append(n.width, 0)
+		n.first = append(n.first, 0)
+	}
+	for i, count := range n.count {
+		if root := n.find(i); root != i {
+			n.count[root] += count
+			n.count[i] = 0
+		}
+	}
+	for _, one := range parts {
+		for _, p := range one.ports() {
+			root := n.find(n.index[p.loc])
+			if p.width == 0 {
+				continue
+			}
+			if n.width[root] != 0 && n.width[root] != p.width {
+				return nil, fmt.Errorf("%s at (%d,%d) has width %d but its net has width %d", one.comp.Name, p.loc.x, p.loc.y, p.width, n.width[root])
+			}
+			n.width[root] = p.width
+		}
+	}
+	for i := range n.parent {
+		if root := n.find(i); root == i {
+			n.width[i] = max(n.width[i], 1)
+			n.first[i] = len(n.bitParent)
+			for range n.width[i] {
+				n.bitParent = append(n.bitParent, len(n.bitParent))
+			}
+		}
+	}
+	for _, one := range parts {
+		switch one.kind {
+		case "Splitter":
+			seen := map[int]int{}
+			for i, end := range one.ends {
+				if end < 0 {
+					continue
+				}
+				n.unionBits(n.bit(one.extra[0].loc, i), n.bit(one.extra[1+end].loc, seen[end]))
+				seen[end]++
+			}
+		case "Tunnel", "Pin":
+			label := one.comp.attr("label", "")
+			if label == "" {
+				continue
+			}
+			p := pin{loc: one.loc, label: label, width: n.width[n.find(n.index[one.loc])]}
+			for i, name := range p.names() {
+				root := n.findBit(n.bit(one.loc, i))
+				if _, ok := n.names[root]; !ok {
+					n.names[root] = name
+				}
+			}
+		}
+	}
+	return n, nil
+}
+
+// ports returns all ports of the part.
+func (p part) ports() []port {
+	var res []port
+	res = append(res, p.ins...)
+	res = append(res, p.outs...)
+	return append(res, p.extra...)
+}
+
+func (n *nets) add(p point) int {
+	if index, ok := n.index[p]; ok {
+		n.count[index]++
+		return index
+	}
+	n.index[p] = len(n.parent)
+	n.parent = append(n.parent, len(n.parent))
+	n.count = append(n.count, 1)
+	return n.index[p]
+}
+
+func (n *nets) find(i int) int {
+	for n.parent[i] != i {
+		n.parent[i] = n.parent[n.parent[i]]
+		i = n.parent[i]
+	}
+	return i
+}
+
+func (n *nets) union(a, b int) {
+	n.parent[n.find(a)] = n.find(b)
+}
+
+func (n *nets) findBit(i int) int {
+	for n.bitParent[i] != i {
+		n.bitParent[i] = n.bitParent[n.bitParent[i]]
+		i = n.bitParent[i]
+	}
+	return i
+}
+
+func (n *nets) unionBits(a, b int) {
+	ra, rb := n.findBit(a), n.findBit(b)
+	// keep the first bit as the root, so the combined end of splitters names the bits
+	if ra > rb {
+		ra, rb = rb, ra
+	}
+	n.bitParent[rb] = ra
+}
+
+func (n *nets) bit(loc point, i int) int {
+	return n.first[n.find(n.index[loc])] + i
+}
+
+// assign uses the given wire for a bit.
+func (n *nets) assign(loc point, i int, w *wire.Wire) error {
+	root := n.findBit(n.bit(loc, i))
+	if other, ok := n.wires[root]; ok && other != w {
+		return fmt.Errorf("pins %q and %q are connected", other.Name, w.Name)
+	}
+	n.wires[root] = w
+	return nil
+}
+
+// bits returns the wires of a location, one per bit.
+func (n *nets) bits(loc point) []*wire.Wire {
+	var res []*wire.Wire
+	for i := range n.width[n.find(n.index[loc])] {
+		root := n.findBit(n.bit(loc, i))
+		if _, ok := n.wires[root]; !ok {
+			n.wires[root] = &wire.Wire{Name: n.names[root]}
+		}
+		res = append(res, n.wires[root])
+	}
+	return res
+}
+
+// connected returns whether anything else is connected to the location.
+func (n *nets) connected(loc point) bool {
+	return n.count[n.find(n.index[loc])] > 1
+}
+
+// input returns the wires of an input port, which are false when unconnected.
+func (n *nets) input(g *group.Group, p port) []*wire.Wire {
+	if !n.connected(p.loc) {
+		var res []*wire.Wire
+		for range p.width {
+			res = append(res, g.False())
+		}
+		return res
+	}
+	return n.bits(p.loc)
+}
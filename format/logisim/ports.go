@@ -0,0 +1,227 @@
+package logisim
+
+import (
+	"cmp"
+	"slices"
+)
+
+// point is a location on the Logisim canvas.
+type point struct {
+	x, y int
+}
+
+func (p point) add(o point) point {
+	return point{p.x + o.x, p.y + o.y}
+}
+
+// rotate rotates an offset from facing east to the given facing, like Logisim Location.rotate.
+func rotate(p point, facing string) point {
+	switch facing {
+	case "north":
+		return point{p.y, -p.x}
+	case "west":
+		return point{-p.x, -p.y}
+	case "south":
+		return point{-p.y, p.x}
+	}
+	return p
+}
+
+// rotateFrom rotates an offset between two facings.
+func rotateFrom(p point, from, to string) point {
+	degrees := map[string]int{"east": 0, "north": 1, "west": 2, "south": 3}
+	turns := (degrees[to] - degrees[from] + 4) % 4
+	for range turns {
+		p = rotate(p, "north")
+	}
+	return p
+}
+
+// gateInput returns the offset of a gate input from its output, following Logisim AbstractGate.getInputOffset.
+func gateInput(facing string, size, inputs, index, axis int, negated bool) point {
+	skipStart, skipDist, skipLowerEven := -5, 10, 10
+	switch {
+	case inputs <= 3 && size < 40:
+	case inputs <= 3 && (size < 60 || inputs <= 2):
+		skipStart, skipDist, skipLowerEven = -10, 20, 20
+	case inputs <= 3:
+		skipStart, skipDist, skipLowerEven = -15, 30, 30
+	case inputs == 4 && size >= 60:
+		skipStart, skipDist, skipLowerEven = -5, 20, 0
+	}
+	var dy int
+	if inputs%2 == 1 {
+		dy = skipStart*(inputs-1) + skipDist*index
+	} else {
+		dy = skipStart*inputs + skipDist*index
+		if index >= inputs/2 {
+			dy += skipLowerEven
+		}
+	}
+	dx := axis
+	if negated {
+		dx += 10
+	}
+	switch facing {
+	case "north":
+		return point{dy, dx}
+	case "south":
+		return point{dy, -dx}
+	case "west":
+		return point{dx, dy}
+	}
+	return point{-dx, dy}
+}
+
+// splitterEnd returns the offset of a splitter end from its combined end, following Logisim SplitterParameters.
+func splitterEnd(facing, appear string, fanout, index int) point {
+	justify := -1
+	switch appear {
+	case "center", "legacy":
+		justify = 0
+	case "right":
+		justify = 1
+	}
+	const width = 20
+	if facing == "north" || facing == "south" {
+		m := 1
+		if facing == "south" {
+			m = -1
+		}
+		var dx int
+		switch {
+		case justify == 0:
+			dx = 10 * ((fanout+1)/2 - 1)
+		case m*justify < 0:
+			dx = -10
+		default:
+			dx = 10 * fanout
+		}
+		return point{dx - 10*index, -m * width}
+	}
+	m := 1
+	if facing == "west" {
+		m = -1
+	}
+	var dy int
+	switch {
+	case justify == 0:
+		dy = -10 * (fanout / 2)
+	case m*justify > 0:
+		dy = 10
+	default:
+		dy = -10 * fanout
+	}
+	return point{m * width, dy + 10*index}
+}
+
+// splitterBits returns the end of each bit, following Logisim SplitterAttributes.computeDistribution.
+func splitterBits(fanout, bits int) []int {
+	var res []int
+	if fanout >= bits {
+		for i := range bits {
+			res = append(res, i)
+		}
+		return res
+	}
+	perEnd, extra := bits/fanout, bits%fanout
+	end, left := -1, 0
+	for range bits {
+		if left == 0 {
+			end++
+			left = perEnd
+			if extra > 0 {
+				left++
+				extra--
+			}
+		}
+		res = append(res, end)
+		left--
+	}
+	return res
+}
+
+// appearancePin is a pin placed on a subcircuit box.
+type appearancePin struct {
+	loc    point
+	facing string
+}
+
+// defaultAppearance returns the offset of each pin from the anchor, following Logisim DefaultAppearance.
+func defaultAppearance(pins []appearancePin) map[point]point {
+	edges := map[string][]appearancePin{}
+	reverse := map[string]string{"east": "west", "west": "east", "north": "south", "south": "north"}
+	for _, pin := range pins {
+		edge := reverse[pin.facing]
+		edges[edge] = append(edges[edge], pin)
+	}
+	for edge, pins := range edges {
+		slices.SortStableFunc(pins, func(a, b appearancePin) int {
+			if edge == "north" || edge == "south" {
+				return cmp.Or(cmp.Compare(a.loc.x, b.loc.x), cmp.Compare(a.loc.y, b.loc.y))
+			}
+			return cmp.Or(cmp.Compare(a.loc.y, b.loc.y), cmp.Compare(a.loc.x, b.loc.x))
+		})
+	}
+	numNorth, numSouth := len(edges["north"]), len(edges["south"])
+	numEast, numWest := len(edges["east"]), len(edges["west"])
+	maxVert, maxHorz := max(numNorth, numSouth), max(numEast, numWest)
+	offsNorth := appearanceOffset(numNorth, numSouth, maxHorz)
+	offsSouth := appearanceOffset(numSouth, numNorth, maxHorz)
+	offsEast := appearanceOffset(numEast, numWest, maxVert)
+	offsWest := appearanceOffset(numWest, numEast, maxVert)
+	width := appearanceDimension(maxVert, maxHorz)
+	height := appearanceDimension(maxHorz, maxVert)
+	var anchor point
+	switch {
+	case numEast > 0:
+		anchor = point{width, offsEast}
+	case numNorth > 0:
+		anchor = point{offsNorth, 0}
+	case numWest > 0:
+		anchor = point{0, offsWest}
+	case numSouth > 0:
+		anchor = point{offsSouth, height}
+	}
+	res := map[point]point{}
+	place := func(pins []appearancePin, start, step point) {
+		for i, pin := range pins {
+			res[pin.loc] = point{start.x + i*step.x - anchor.x, start.y + i*step.y - anchor.y}
+		}
+	}
+	place(edges["west"], point{0, offsWest}, point{0, 10})
+	place(edges["east"], point{width, offsEast}, point{0, 10})
+	place(edges["north"], point{offsNorth, 0}, point{10, 0})
+	place(edges["south"], point{offsSouth, height}, point{10, 0})
+	return res
+}
+
+func appearanceDimension(maxThis, maxOthers int) int {
+	switch {
+	case maxThis < 3:
+		return 30
+	case maxOthers == 0:
+		return 10 * maxThis
+	}
+	return 10*maxThis + 10
+}
+
+func appearanceOffset(numFacing, numOpposite, maxOthers int) int {
+	maxThis := max(numFacing, numOpposite)
+	var maxOffs int
+	switch maxThis {
+	case 0, 1:
+		maxOffs = 10
+		if maxOthers == 0 {
+			maxOffs = 15
+		}
+	case 2:
+		maxOffs = 10
+	default:
+		maxOffs = 10
+		if maxOthers == 0 {
+			maxOffs = 5
+		}
+	}
+	return maxOffs + 10*((maxThis-numFacing)/2)
+}
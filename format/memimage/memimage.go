@@ -0,0 +1,174 @@
+// Package memimage reads and writes memory images, which contain the words loaded into a RAM starting at address 0.
+package memimage
+
+import (
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Read returns the words of the image, choosing the format by the file extension:
+// Intel-HEX for .hex and .ihex, raw binary for .bin, and text otherwise.
+func Read(name string, data []byte) ([]uint64, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".hex", ".ihex":
+		return IntelHEX(string(data))
+	case ".bin":
+		return Binary(data), nil
+	}
+	return Text(string(data))
+}
+
+// Binary returns one word per byte.
+func Binary(data []byte) []uint64 {
+	var res []uint64
+	for _, one := range data {
+		res = append(res, uint64(one))
+	}
+	return res
+}
+
+// Text returns one word per number separated by spaces, in Go syntax such as 5, 0x5 or 0b101.
+//
+// Comments start with "#" or ";" and run to the end of the line.
+func Text(data string) ([]uint64, error) {
+	var res []uint64
+	for i, line := range strings.Split(data, "\n") {
+		if index := strings.IndexAny(line, "#;"); index >= 0 {
+			line = line[:index]
+		}
+		for _, field := range strings.Fields(line) {
+			word, err := strconv.ParseUint(field, 0, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid word %q", i+1, field)
+			}
+			res = append(res, word)
+		}
+	}
+	return res, nil
+}
+
+// MaxWords is the most words an Intel-HEX image can address.
+const MaxWords = 1 << 20
+
+// Intel-HEX record types.
+const (
+	recordData                  = 0x00
+	recordEOF                   = 0x01
+	recordExtendedSegment       = 0x02
+	recordStartSegment          = 0x03
+	recordExtendedLinearAddress = 0x04
+	recordStartLinearAddress    = 0x05
+)
+
+// IntelHEX returns one word per byte of the data records, with 0 in the addresses they skip.
+func IntelHEX(data string) ([]uint64, error) {
+	var res []uint64
+	base := 0
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, ":") {
+			return nil, fmt.Errorf("line %d: want record starting with \":\" got %q", i+1, line)
+		}
+		record, err := hex.DecodeString(line[1:])
+		if err != nil || len(record) < 5 || len(record) != 5+int(record[0]) {
+			return nil, fmt.Errorf("line %d: invalid record %q", i+1, line)
+		}
+		var sum byte
+		for _, one := range record {
+			sum += one
+		}
+		if sum != 0 {
+			return nil, fmt.Errorf("line %d: invalid checksum in %q", i+1, line)
+		}
+		address, payload := int(record[1])<<8|int(record[2]), record[4:len(record)-1]
+		switch record[3] {
+		case recordData:
+			if base+address+len(payload) > MaxWords {
+				return nil, fmt.Errorf("line %d: address %#x past %#x words", i+1, base+address+len(payload)-1, MaxWords)
+			}
+			for j, one := range payload {
+				for len(res) <= base+address+j {
+					res = append(res, 0)
+				}
+				res[base+address+j] = uint64(one)
+			}
+		case recordEOF:
+			return res, nil
+		case recordExtendedSegment, recordExtendedLinearAddress:
+			if len(payload) != 2 {
+				return nil, fmt.Errorf("line %d: invalid address record %q", i+1, line)
+			}
+			base = int(payload[0])<<8 | int(payload[1])
+			if record[3] == recordExtendedSegment {
+				base <<= 4
+			} else {
+				base <<= 16
+			}
+		case recordStartSegment, recordStartLinearAddress:
+		default:
+			return nil, fmt.Errorf("line %d: unsupported record type %#02x", i+1, record[3])
+		}
+	}
+	return nil, fmt.Errorf("missing end of file record")
+}
+
+// Write returns the image of the words, choosing the format by the file extension like Read.
+//
+// Intel-HEX and raw binary images need words of at most 8 bits.
+func Write(name string, words []uint64) ([]byte, error) {
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == ".hex" || ext == ".ihex" || ext == ".bin" {
+		for i, word := range words {
+			if word > 0xff {
+				return nil, fmt.Errorf("word %d is %#x, want at most 8 bits in %s images", i, word, ext)
+			}
+		}
+	}
+	switch ext {
+	case ".hex", ".ihex":
+		return []byte(writeIntelHEX(words)), nil
+	case ".bin":
+		var res []byte
+		for _, word := range words {
+			res = append(res, byte(word))
+		}
+		return res, nil
+	}
+	var res strings.Builder
+	for _, word := range words {
+		fmt.Fprintf(&res, "%#02x\n", word)
+	}
+	return []byte(res.String()), nil
+}
+
+// writeIntelHEX returns data records of up to 16 bytes, preceded by extended linear address records past 64K,
+// followed by the end of file record.
+func writeIntelHEX(words []uint64) string {
+	var res strings.Builder
+	write := func(record ...byte) {
+		var sum byte
+		for _, one := range record {
+			sum += one
+		}
+		fmt.Fprintf(&res, ":%s\n", strings.ToUpper(hex.EncodeToString(append(record, -sum))))
+	}
+	for start := 0; start < len(words); start += 16 {
+		if start > 0 && start&0xffff == 0 {
+			write(2, 0, 0, recordExtendedLinearAddress, byte(start>>24), byte(start>>16))
+		}
+		end := min(start+16, len(words))
+		record := []byte{byte(end - start), byte(start >> 8), byte(start), recordData}
+		for _, word := range words[start:end] {
+			record = append(record, byte(word))
+		}
+		write(record...)
+	}
+	write(0, 0, 0, recordEOF)
+	return res.String()
+}
@@ -0,0 +1,102 @@
+package memimage
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	for _, in := range []struct {
+		name string
+		data string
+		want []uint64
+	}{{
+		name: "image.txt",
+		data: "1 0x2 0b11 # comment\n; another comment\n4\n",
+		want: []uint64{1, 2, 3, 4},
+	}, {
+		name: "image.bin",
+		data: "\x01\x02\xff",
+		want: []uint64{1, 2, 255},
+	}, {
+		name: "image.hex",
+		data: ":0300000001020FEB\n:01000500AA50\n:00000001FF\n",
+		want: []uint64{1, 2, 15, 0, 0, 0xaa},
+	}, {
+		name: "image.ihex",
+		data: ":020000040000FA\n:0100010007F7\n:00000001FF\n",
+		want: []uint64{0, 7},
+	}} {
+		got, err := Read(in.name, []byte(in.data))
+		if err != nil {
+			t.Errorf("Read(%q) got err %v", in.name, err)
+			continue
+		}
+		if !slices.Equal(got, in.want) {
+			t.Errorf("Read(%q) want %v got %v", in.name, in.want, got)
+		}
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	for _, in := range []struct {
+		name string
+		data string
+		want string
+	}{{
+		name: "image.txt",
+		data: "1\nx\n",
+		want: `line 2: invalid word "x"`,
+	}, {
+		name: "image.hex",
+		data: ":0300000001020FEC\n",
+		want: "line 1: invalid checksum",
+	}, {
+		name: "image.hex",
+		data: "0300000001020FEB\n",
+		want: "line 1: want record",
+	}, {
+		name: "image.hex",
+		data: ":0300000001020FEB\n",
+		want: "missing end of file record",
+	}, {
+		name: "image.hex",
+		data: ":02000004FFFFFC\n:0100000007F8\n",
+		want: "line 2: address",
+	}} {
+		_, err := Read(in.name, []byte(in.data))
+		if err == nil || !strings.Contains(err.Error(), in.want) {
+			t.Errorf("Read(%q, %q) want err containing %q got %v", in.name, in.data, in.want, err)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	long := make([]uint64, 0x10020)
+	for i := range long {
+		long[i] = uint64(i % 251)
+	}
+	for _, in := range []struct {
+		name  string
+		words []uint64
+	}{
+		{"image.txt", []uint64{1, 0x1ff, 0}},
+		{"image.bin", []uint64{1, 2, 255}},
+		{"image.hex", []uint64{0x55, 0x2e, 0, 0xf0}},
+		{"image.hex", long},
+	} {
+		data, err := Write(in.name, in.words)
+		if err != nil {
+			t.Errorf("Write(%q) got err %v", in.name, err)
+			continue
+		}
+		got, err := Read(in.name, data)
+		if err != nil || !slices.Equal(got, in.words) {
+			t.Errorf("Read(Write(%q)) want %d words got %d words and err %v", in.name, len(in.words), len(got), err)
+		}
+	}
+	if _, err := Write("image.hex", []uint64{0x100}); err == nil || !strings.Contains(err.Error(), "at most 8 bits") {
+		t.Errorf("Write(0x100) want err got %v", err)
+	}
+}
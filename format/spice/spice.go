@@ -0,0 +1,332 @@
+// Package spice exports the transistor network of a circuit as a SPICE netlist.
+package spice
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kssilveira/circuit-engine/circuit"
+	"github.com/kssilveira/circuit-engine/component"
+	"github.com/kssilveira/circuit-engine/group"
+	"github.com/kssilveira/circuit-engine/jointwire"
+	"github.com/kssilveira/circuit-engine/netlist"
+	"github.com/kssilveira/circuit-engine/transistor"
+	"github.com/kssilveira/circuit-engine/wire"
+)
+
+const (
+	// Vcc is the supply voltage.
+	Vcc = 5.0
+	// Step is the time in nanoseconds each input vector is applied.
+	Step = 1000
+	// Rise is the rise and fall time in nanoseconds of the input sources.
+	Rise = 10
+	// BaseResistor is placed between each base and the net driving it.
+	BaseResistor = "10k"
+	// CollectorResistor pulls up each used collector output.
+	CollectorResistor = "1k"
+	// PulldownResistor pulls down each net that is not a supply, an input or a collector.
+	PulldownResistor = "10k"
+	// Model is the NPN transistor model.
+	Model = ".model npn NPN(IS=1e-14 BF=100)"
+)
+
+// Export returns the transistor network as a SPICE netlist driven by the given input vectors.
+//
+// Each transistor becomes an NPN device with a resistor in series with its base.
+// When the collector output is used, the device collector is the collector output,
+// pulled up by a resistor to the collector net; otherwise the device collector is the collector net.
+// Joint wires are junctions, so their nets become a single node (wired or for emitters, wired and for collectors).
+// Undriven nets become the vcc supply node when true, ground when they are the Gnd, and pulled down nodes otherwise.
+// Each input becomes a piecewise linear source applying one vector per Step.
+// The circuit is simulated with the same vectors, and the expected outputs are written as comments.
+// The inputs are restored afterwards, and the vectors must have one digit 0 or 1 per input.
+func Export(c *circuit.Circuit, vectors []string) (string, error) {
+	for _, vector := range vectors {
+		if len(vector) != len(c.Inputs) || strings.Trim(vector, "01") != "" {
+			return "", fmt.Errorf("invalid vector %q, want %d digits 0 or 1", vector, len(c.Inputs))
+		}
+	}
+	n, err := newNodes(c)
+	if err != nil {
+		return "", err
+	}
+	// the simulation sets the inputs, so restore them afterwards
+	var previous []bool
+	for _, input := range c.Inputs {
+		previous = append(previous, input.Bit.SilentGet())
+	}
+	defer func() {
+		for i, input := range c.Inputs {
+			input.Bit.SilentSet(previous[i])
+		}
+	}()
+	res := []string{
+		"* circuit-engine transistor network",
+		Model,
+		fmt.Sprintf("VCC vcc 0 DC %g", Vcc),
+	}
+	for i, t := range n.transistors {
+		base := fmt.Sprintf("b%d", i+1)
+		collector := n.node(t.Collector)
+		if used(t.CollectorOut) {
+			res = append(res, fmt.Sprintf("RC%d %s %s %s", i+1, collector, n.node(t.CollectorOut), CollectorResistor))
+			collector = n.node(t.CollectorOut)
+		}
+		res = append(res,
+			fmt.Sprintf("RB%d %s %s %s", i+1, n.node(t.Base), base, BaseResistor),
+			fmt.Sprintf("Q%d %s %s %s npn", i+1, collector, base, n.node(t.Emitter)))
+	}
+	for i, node := range n.pulldowns() {
+		res = append(res, fmt.Sprintf("RP%d %s 0 %s", i+1, node, PulldownResistor))
+	}
+	var valid []string
+	for _, vector := range vectors {
+		c.SetInputs(vector)
+		if c.IsValid() {
+			valid = append(valid, vector)
+		}
+	}
+	for i, input := range c.Inputs {
+		var points []string
+		for j, vector := range valid {
+			start := j * Step
+			if j > 0 {
+				start += Rise
+			}
+			v := 0.0
+			if vector[i] == '1' {
+				v = Vcc
+			}
+			points = append(points, fmt.Sprintf("%dn %g %dn %g", start, v, (j+1)*Step, v))
+		}
+		res = append(res, fmt.Sprintf("V%d %s 0 PWL(%s)", i+1, n.node(input), strings.Join(points, " ")))
+	}
+	if len(valid) > 0 {
+		// start from the state the engine reaches with the first vector
+		c.SetInputs(valid[0])
+		c.Update()
+		values := n.values()
+		var ics []string
+		for _, node := range n.internal() {
+			v := 0.0
+			if values[node] {
+				v = Vcc
+			}
+			ics = append(ics, fmt.Sprintf("v(%s)=%g", node, v))
+		}
+		if len(ics) > 0 {
+			res = append(res, ".ic "+strings.Join(ics, " "))
+		}
+	}
+	for j, vector := range valid {
+		c.SetInputs(vector)
+		c.Update()
+		res = append(res, fmt.Sprintf("* expected at %dn: %s", j*Step+Step/2, c.StringForUnitTest()))
+	}
+	var outputs []string
+	for _, output := range c.Outputs {
+		outputs = append(outputs, fmt.Sprintf("v(%s)", n.node(output)))
+	}
+	res = append(res, fmt.Sprintf(".tran %dn %dn", Step/100, len(valid)*Step))
+	if len(outputs) > 0 {
+		res = append(res, ".print tran "+strings.Join(outputs, " "))
+	}
+	res = append(res, ".end")
+	return strings.Join(res, "\n") + "\n", nil
+}
+
+// nodes maps nets to SPICE nodes.
+type nodes struct {
+	nets        *netlist.Netlist
+	parent      []int
+	names       map[int]string
+	transistors []*transistor.Transistor
+	driven      map[*wire.Wire]bool
+	inputs      map[*wire.Wire]bool
+	// collectors contains the collector nets, whose Gnd is set by the transistors
+	collectors map[*wire.Wire]bool
+	// devices contains the nodes connected to a device collector
+	devices map[string]bool
+}
+
+func newNodes(c *circuit.Circuit) (*nodes, error) {
+	n := &nodes{
+		nets:       netlist.New(c),
+		names:      map[int]string{},
+		driven:     map[*wire.Wire]bool{},
+		inputs:     map[*wire.Wire]bool{},
+		collectors: map[*wire.Wire]bool{},
+		devices:    map[string]bool{},
+	}
+	for i := range n.nets.Nets {
+		n.parent = append(n.parent, i)
+	}
+	for _, input := range c.Inputs {
+		n.inputs[input] = true
+	}
+	var err error
+	netlist.Walk(c.Components, func(one component.Component) {
+		switch one := one.(type) {
+		case *group.Group:
+		case *transistor.Transistor:
+			n.transistors = append(n.transistors, one)
+			n.collectors[one.Collector] = true
+			n.driven[one.Emitter] = true
+			n.driven[one.CollectorOut] = true
+		case *jointwire.JointWire:
+			n.driven[one.Res] = true
+			n.union(one.Res, one.A)
+			n.union(one.Res, one.B)
+		default:
+			err = fmt.Errorf("unsupported component %T", one)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := n.nameNodes(); err != nil {
+		return nil, err
+	}
+	for _, t := range n.transistors {
+		if used(t.CollectorOut) {
+			n.devices[n.node(t.CollectorOut)] = true
+		} else {
+			n.devices[n.node(t.Collector)] = true
+		}
+	}
+	return n, nil
+}
+
+func (n *nodes) find(i int) int {
+	for n.parent[i] != i {
+		n.parent[i] = n.parent[n.parent[i]]
+		i = n.parent[i]
+	}
+	return i
+}
+
+func (n *nodes) union(a, b *wire.Wire) {
+	ia, _ := n.nets.Index(a)
+	ib, _ := n.nets.Index(b)
+	ra, rb := n.find(ia), n.find(ib)
+	// keep the first net as the root, so inputs and outputs name their nodes
+	if ra > rb {
+		ra, rb = rb, ra
+	}
+	n.parent[rb] = ra
+}
+
+// nameNodes names the supply, ground and input nodes, and uses the root net name for the others.
+func (n *nodes) nameNodes() error {
+	supply := map[int]string{}
+	for _, net := range n.nets.Nets {
+		if n.inputs[net] {
+			continue
+		}
+		root := n.root(net)
+		switch {
+		case net.Gnd.SilentGet() && !n.collectors[net]:
+			supply[root] = "0"
+		case net.Bit.SilentGet() && !n.driven[net]:
+			supply[root] = "vcc"
+		}
+	}
+	count := map[string]int{}
+	for i, net := range n.nets.Nets {
+		if n.find(i) == i {
+			count[strings.ToLower(identifier(net.Name))]++
+		}
+	}
+	for i, net := range n.nets.Nets {
+		if n.find(i) != i {
+			continue
+		}
+		if name, ok := supply[i]; ok {
+			n.names[i] = name
+			continue
+		}
+		n.names[i] = identifier(net.Name)
+		if lower := strings.ToLower(n.names[i]); count[lower] > 1 || lower == "vcc" || lower == "0" {
+			n.names[i] = fmt.Sprintf("%s_%d", n.names[i], i)
+		}
+	}
+	for i, net := range n.nets.Nets {
+		if n.inputs[net] && (n.names[n.find(i)] == "vcc" || n.names[n.find(i)] == "0") {
+			return fmt.Errorf("input %q is joined to a supply", net.Name)
+		}
+	}
+	return nil
+}
+
+func (n *nodes) root(w *wire.Wire) int {
+	index, _ := n.nets.Index(w)
+	return n.find(index)
+}
+
+func (n *nodes) node(w *wire.Wire) string {
+	return n.names[n.root(w)]
+}
+
+// internal returns the nodes that are not supplies or inputs, in first-use order.
+func (n *nodes) internal() []string {
+	var res []string
+	seen := map[string]bool{}
+	for _, net := range n.nets.Nets {
+		node := n.node(net)
+		if seen[node] || node == "vcc" || node == "0" || !used(net) {
+			continue
+		}
+		seen[node] = true
+		if !n.inputs[net] {
+			res = append(res, node)
+		}
+	}
+	return res
+}
+
+// pulldowns returns the internal nodes without a collector, which would otherwise float.
+func (n *nodes) pulldowns() []string {
+	inputs := map[string]bool{}
+	for net := range n.inputs {
+		inputs[n.node(net)] = true
+	}
+	var res []string
+	for _, node := range n.internal() {
+		if !n.devices[node] && !inputs[node] {
+			res = append(res, node)
+		}
+	}
+	return res
+}
+
+// values returns the value of the first net of each node, usually the joint wire result.
+func (n *nodes) values() map[string]bool {
+	res := map[string]bool{}
+	for i, net := range n.nets.Nets {
+		if n.find(i) == i {
+			res[n.names[i]] = net.Bit.SilentGet()
+		}
+	}
+	return res
+}
+
+func used(w *wire.Wire) bool {
+	return w != nil && w.Name != "Unused"
+}
+
+// identifier replaces the characters that are not valid in node names.
+func identifier(name string) string {
+	var res strings.Builder
+	for _, r := range name {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			res.WriteRune(r)
+		} else {
+			res.WriteRune('_')
+		}
+	}
+	if res.Len() == 0 || (name[0] >= '0' && name[0] <= '9') {
+		return "n" + res.String()
+	}
+	return res.String()
+}
@@ -0,0 +1,60 @@
+package spice
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kssilveira/circuit-engine/circuit"
+	"github.com/kssilveira/circuit-engine/config"
+	"github.com/kssilveira/circuit-engine/lib"
+	"github.com/kssilveira/circuit-engine/wire"
+)
+
+func TestExport(t *testing.T) {
+	c := circuit.NewCircuit(config.Config{IsUnitTest: true})
+	c.Outs(lib.Example(c, "Nor"))
+	got, err := Export(c, []string{"00", "01", "10", "11"})
+	if err != nil {
+		t.Fatalf("Export got err %v", err)
+	}
+	want := `* circuit-engine transistor network
+.model npn NPN(IS=1e-14 BF=100)
+VCC vcc 0 DC 5
+RC1 vcc NOR_a_b_ 1k
+RB1 a b1 10k
+Q1 NOR_a_b_ b1 0 npn
+RC2 vcc NOR_a_b_ 1k
+RB2 b b2 10k
+Q2 NOR_a_b_ b2 0 npn
+V1 a 0 PWL(0n 0 1000n 0 1010n 0 2000n 0 2010n 5 3000n 5 3010n 5 4000n 5)
+V2 b 0 PWL(0n 0 1000n 0 1010n 5 2000n 5 2010n 0 3000n 0 3010n 5 4000n 5)
+.ic v(NOR_a_b_)=5
+* expected at 500n: 00=>1
+* expected at 1500n: 01=>0
+* expected at 2500n: 10=>0
+* expected at 3500n: 11=>0
+.tran 10n 4000n
+.print tran v(NOR_a_b_)
+.end
+`
+	if got != want {
+		t.Errorf("Export want\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestExportInputs(t *testing.T) {
+	c := circuit.NewCircuit(config.Config{IsUnitTest: true})
+	c.Outs(lib.Example(c, "Nor"))
+	for _, vector := range []string{"0", "001", "0x"} {
+		if _, err := Export(c, []string{"00", vector}); err == nil || !strings.Contains(err.Error(), "want 2 digits") {
+			t.Errorf("Export(%q) want err got %v", vector, err)
+		}
+	}
+	c.SetInputs("10")
+	if _, err := Export(c, []string{"00", "11"}); err != nil {
+		t.Fatalf("Export got err %v", err)
+	}
+	if a, b := c.Inputs[0].Bit.SilentGet(), c.Inputs[1].Bit.SilentGet(); !a || b {
+		t.Errorf("Export want inputs restored to 10 got %s%s", wire.BoolToString(a), wire.BoolToString(b))
+	}
+}
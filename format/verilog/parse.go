@@ -0,0 +1,608 @@
+package verilog
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/kssilveira/circuit-engine/circuit"
+	"github.com/kssilveira/circuit-engine/group"
+	"github.com/kssilveira/circuit-engine/lib/gate"
+	"github.com/kssilveira/circuit-engine/wire"
+)
+
+// Import adds the given gate-level Verilog design to the circuit.
+//
+// The supported subset contains modules with scalar ports and wires, continuous assignments
+// using & | ^ ~ and parentheses, the and, or, nand, nor, xor, xnor, not and buf primitives,
+// and module instances with named or positional connections.
+// The top module is the given one, or the only module that is not instantiated.
+// Its inputs and outputs become the circuit inputs and outputs.
+func Import(c *circuit.Circuit, src, top string) error {
+	modules, err := parse(src)
+	if err != nil {
+		return err
+	}
+	m, err := findTop(modules, top)
+	if err != nil {
+		return err
+	}
+	nets := map[string]*wire.Wire{}
+	for _, port := range m.ports {
+		switch m.directions[port] {
+		case "input":
+			nets[port] = c.In(port)
+		case "output":
+			nets[port] = &wire.Wire{Name: port}
+			c.Out(nets[port])
+		default:
+			return fmt.Errorf("module %q port %q has no direction", m.name, port)
+		}
+	}
+	b := &builder{modules: modules, driven: map[*wire.Wire]bool{}}
+	return b.build(c.Group(m.name), m, nets, nil)
+}
+
+func findTop(modules map[string]*vmodule, top string) (*vmodule, error) {
+	if top != "" {
+		m, ok := modules[top]
+		if !ok {
+			return nil, fmt.Errorf("top module %q not found", top)
+		}
+		return m, nil
+	}
+	instantiated := map[string]bool{}
+	for _, m := range modules {
+		for _, instance := range m.instances {
+			instantiated[instance.module] = true
+		}
+	}
+	var candidates []string
+	for name := range modules {
+		if !instantiated[name] {
+			candidates = append(candidates, name)
+		}
+	}
+	slices.Sort(candidates)
+	if len(candidates) != 1 {
+		return nil, fmt.Errorf("want exactly one top module, got %q", candidates)
+	}
+	return modules[candidates[0]], nil
+}
+
+type vmodule struct {
+	name       string
+	ports      []string
+	directions map[string]string
+	statements []statement
+	instances  []instance
+}
+
+// statement is either an assignment or a gate primitive.
+type statement struct {
+	primitive string
+	outs      []string
+	ins       []expr
+	line      int
+}
+
+type instance struct {
+	module      string
+	name        string
+	positional  []string
+	connections map[string]string
+	line        int
+}
+
+// expr is an identifier, a constant ("1'b0" or "1'b1"), or an operator with its operands.
+type expr struct {
+	op   string
+	name string
+	args []expr
+}
+
+type builder struct {
+	modules map[string]*vmodule
+	driven  map[*wire.Wire]bool
+}
+
+func (b *builder) build(g *group.Group, m *vmodule, nets map[string]*wire.Wire, stack []string) error {
+	if slices.Contains(stack, m.name) {
+		return fmt.Errorf("recursive instance of module %q", m.name)
+	}
+	stack = append(stack, m.name)
+	net := func(name string) *wire.Wire {
+		if _, ok := nets[name]; !ok {
+			nets[name] = &wire.Wire{Name: name}
+		}
+		return nets[name]
+	}
+	for _, s := range m.statements {
+		var ins []*wire.Wire
+		for _, in := range s.ins {
+			ins = append(ins, b.expr(g, in, net))
+		}
+		res := apply(g, s.primitive, ins)
+		for _, out := range s.outs {
+			w := net(out)
+			if b.driven[w] {
+				return fmt.Errorf("line %d: multiple drivers for %q", s.line, out)
+			}
+			b.driven[w] = true
+			g.JointWire(w, res, res)
+		}
+	}
+	for _, inst := range m.instances {
+		child, ok := b.modules[inst.module]
+		if !ok {
+			return fmt.Errorf("line %d: unknown module %q", inst.line, inst.module)
+		}
+		if len(inst.positional) > len(child.ports) {
+			return fmt.Errorf("line %d: module %q has %d ports, got %d", inst.line, child.name, len(child.ports), len(inst.positional))
+		}
+		childNets := map[string]*wire.Wire{}
+		for i, name := range inst.positional {
+			childNets[child.ports[i]] = net(name)
+		}
+		for port, name := range inst.connections {
+			if !slices.Contains(child.ports, port) {
+				return fmt.Errorf("line %d: module %q has no port %q", inst.line, child.name, port)
+			}
+			childNets[port] = net(name)
+		}
+		if err := b.build(g.Group(inst.name), child, childNets, stack); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *builder) expr(g *group.Group, e expr, net func(string) *wire.Wire) *wire.Wire {
+	switch e.op {
+	case "":
+		return net(e.name)
+	case "1'b0":
+		return g.False()
+	case "1'b1":
+		return g.True()
+	}
+	var args []*wire.Wire
+	for _, arg := range e.args {
+		args = append(args, b.expr(g, arg, net))
+	}
+	return apply(g, e.op, args)
+}
+
+// apply adds the gates computing the primitive or operator over the arguments.
+func apply(g *group.Group, op string, args []*wire.Wire) *wire.Wire {
+	fold := func(fn func(*group.Group, *wire.Wire, *wire.Wire) *wire.Wire) *wire.Wire {
+		res := args[0]
+		for _, arg := range args[1:] {
+			res = fn(g, res, arg)
+		}
+		return res
+	}
+	switch op {
+	case "~", "not":
+		return gate.Not(g, args[0])
+	case "&", "and":
+		return fold(gate.And)
+	case "|", "or":
+		return fold(gate.Or)
+	case "^", "xor":
+		return fold(gate.Xor)
+	case "nand":
+		if len(args) == 2 {
+			return gate.Nand(g, args[0], args[1])
+		}
+		return gate.Not(g, fold(gate.And))
+	case "nor":
+		if len(args) == 2 {
+			return gate.Nor(g, args[0], args[1])
+		}
+		return gate.Not(g, fold(gate.Or))
+	case "xnor":
+		return gate.Not(g, fold(gate.Xor))
+	}
+	// buf and plain assignments
+	return args[0]
+}
+
+var (
+	primitives = map[string]int{
+		"and": 2, "or": 2, "nand": 2, "nor": 2, "xor": 2, "xnor": 2, "not": 1, "buf": 1,
+	}
+	switches = map[string]bool{
+		"nmos": true, "pmos": true, "cmos": true, "tran": true, "pullup": true, "pulldown": true,
+	}
+)
+
+type token struct {
+	text string
+	line int
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func parse(src string) (map[string]*vmodule, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	res := map[string]*vmodule{}
+	for !p.done() {
+		m, err := p.module()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := res[m.name]; ok {
+			return nil, fmt.Errorf("duplicate module %q", m.name)
+		}
+		res[m.name] = m
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no modules found")
+	}
+	return res, nil
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.pos].text
+}
+
+func (p *parser) line() int {
+	if p.done() {
+		if len(p.tokens) == 0 {
+			return 0
+		}
+		return p.tokens[len(p.tokens)-1].line
+	}
+	return p.tokens[p.pos].line
+}
+
+func (p *parser) next() string {
+	res := p.peek()
+	p.pos++
+	return res
+}
+
+func (p *parser) expect(want string) error {
+	line := p.line()
+	if got := p.next(); got != want {
+		return fmt.Errorf("line %d: want %q got %q", line, want, got)
+	}
+	return nil
+}
+
+func (p *parser) ident() (string, error) {
+	line := p.line()
+	res := p.next()
+	if !isIdent(res) {
+		return "", fmt.Errorf("line %d: want identifier got %q", line, res)
+	}
+	return res, nil
+}
+
+func (p *parser) module() (*vmodule, error) {
+	if err := p.expect("module"); err != nil {
+		return nil, err
+	}
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	m := &vmodule{name: name, directions: map[string]string{}}
+	if p.peek() == "(" {
+		p.next()
+		direction := ""
+		for p.peek() != ")" {
+			if p.peek() == "input" || p.peek() == "output" {
+				direction = p.next()
+			}
+			if p.peek() == "wire" {
+				p.next()
+			}
+			port, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			m.ports = append(m.ports, port)
+			if direction != "" {
+				m.directions[port] = direction
+			}
+			if p.peek() != "," {
+				break
+			}
+			p.next()
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect(";"); err != nil {
+		return nil, err
+	}
+	for p.peek() != "endmodule" {
+		if p.done() {
+			return nil, fmt.Errorf("module %q: missing endmodule", name)
+		}
+		if err := p.item(m); err != nil {
+			return nil, err
+		}
+	}
+	p.next()
+	return m, nil
+}
+
+func (p *parser) item(m *vmodule) error {
+	line := p.line()
+	word := p.next()
+	switch {
+	case word == "input" || word == "output" || word == "wire":
+		if p.peek() == "wire" {
+			p.next()
+		}
+		names, err := p.idents(";")
+		if err != nil {
+			return err
+		}
+		if word != "wire" {
+			for _, name := range names {
+				if !slices.Contains(m.ports, name) {
+					return fmt.Errorf("line %d: %q is not a port of module %q", line, name, m.name)
+				}
+				m.directions[name] = word
+			}
+		}
+		return p.expect(";")
+	case word == "assign":
+		for {
+			lhs, err := p.ident()
+			if err != nil {
+				return err
+			}
+			if err := p.expect("="); err != nil {
+				return err
+			}
+			rhs, err := p.expr()
+			if err != nil {
+				return err
+			}
+			m.statements = append(m.statements, statement{outs: []string{lhs}, ins: []expr{rhs}, line: line})
+			if p.peek() != "," {
+				break
+			}
+			p.next()
+		}
+		return p.expect(";")
+	case primitives[word] > 0:
+		if p.peek() != "(" {
+			if _, err := p.ident(); err != nil {
+				return err
+			}
+		}
+		if err := p.expect("("); err != nil {
+			return err
+		}
+		var args []expr
+		for {
+			arg, err := p.expr()
+			if err != nil {
+				return err
+			}
+			args = append(args, arg)
+			if p.peek() != "," {
+				break
+			}
+			p.next()
+		}
+		if err := p.expect(")"); err != nil {
+			return err
+		}
+		outs := 1
+		if word == "not" || word == "buf" {
+			outs = len(args) - 1
+		}
+		if len(args)-outs < primitives[word] || outs < 1 {
+			return fmt.Errorf("line %d: not enough terminals for %q", line, word)
+		}
+		s := statement{primitive: word, ins: args[outs:], line: line}
+		for _, out := range args[:outs] {
+			if out.op != "" {
+				return fmt.Errorf("line %d: %q output must be a net", line, word)
+			}
+			s.outs = append(s.outs, out.name)
+		}
+		m.statements = append(m.statements, s)
+		return p.expect(";")
+	case switches[word]:
+		return fmt.Errorf("line %d: switch-level primitive %q is not supported", line, word)
+	case isIdent(word):
+		inst := instance{module: word, connections: map[string]string{}, line: line}
+		name, err := p.ident()
+		if err != nil {
+			return err
+		}
+		inst.name = name
+		if err := p.expect("("); err != nil {
+			return err
+		}
+		for p.peek() != ")" {
+			if p.peek() == "." {
+				p.next()
+				port, err := p.ident()
+				if err != nil {
+					return err
+				}
+				if err := p.expect("("); err != nil {
+					return err
+				}
+				if p.peek() != ")" {
+					net, err := p.ident()
+					if err != nil {
+						return err
+					}
+					inst.connections[port] = net
+				}
+				if err := p.expect(")"); err != nil {
+					return err
+				}
+			} else {
+				net, err := p.ident()
+				if err != nil {
+					return err
+				}
+				inst.positional = append(inst.positional, net)
+			}
+			if p.peek() != "," {
+				break
+			}
+			p.next()
+		}
+		if err := p.expect(")"); err != nil {
+			return err
+		}
+		m.instances = append(m.instances, inst)
+		return p.expect(";")
+	}
+	return fmt.Errorf("line %d: unsupported %q", line, word)
+}
+
+func (p *parser) idents(end string) ([]string, error) {
+	var res []string
+	for {
+		name, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, name)
+		if p.peek() == end {
+			return res, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// expr parses operators with the Verilog precedence: ~ then & then ^ then |.
+func (p *parser) expr() (expr, error) {
+	return p.binary([]string{"|", "^", "&"})
+}
+
+func (p *parser) binary(ops []string) (expr, error) {
+	if len(ops) == 0 {
+		return p.unary()
+	}
+	res, err := p.binary(ops[1:])
+	if err != nil {
+		return expr{}, err
+	}
+	for p.peek() == ops[0] {
+		p.next()
+		arg, err := p.binary(ops[1:])
+		if err != nil {
+			return expr{}, err
+		}
+		res = expr{op: ops[0], args: []expr{res, arg}}
+	}
+	return res, nil
+}
+
+func (p *parser) unary() (expr, error) {
+	line := p.line()
+	word := p.next()
+	switch {
+	case word == "~":
+		arg, err := p.unary()
+		if err != nil {
+			return expr{}, err
+		}
+		return expr{op: "~", args: []expr{arg}}, nil
+	case word == "(":
+		res, err := p.expr()
+		if err != nil {
+			return expr{}, err
+		}
+		return res, p.expect(")")
+	case word == "0" || word == "1'b0" || word == "1'd0" || word == "1'h0":
+		return expr{op: "1'b0"}, nil
+	case word == "1" || word == "1'b1" || word == "1'd1" || word == "1'h1":
+		return expr{op: "1'b1"}, nil
+	case isIdent(word):
+		return expr{name: word}, nil
+	}
+	return expr{}, fmt.Errorf("line %d: unexpected %q in expression", line, word)
+}
+
+func isIdent(s string) bool {
+	if s == "" || s == "module" || s == "endmodule" || s == "assign" || s == "input" || s == "output" || s == "wire" {
+		return false
+	}
+	if s[0] == '\\' {
+		return len(s) > 1
+	}
+	for i, r := range s {
+		if !(r == '_' || unicode.IsLetter(r) || (i > 0 && (unicode.IsDigit(r) || r == '$'))) {
+			return false
+		}
+	}
+	return true
+}
+
+func lex(src string) ([]token, error) {
+	var res []token
+	line := 1
+	for i := 0; i < len(src); {
+		ch := src[i]
+		switch {
+		case ch == '\n':
+			line++
+			i++
+		case ch == ' ' || ch == '\t' || ch == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += 2 + end + 2
+		case ch == '\\':
+			// escaped identifiers end at white space
+			start := i
+			for i < len(src) && !unicode.IsSpace(rune(src[i])) {
+				i++
+			}
+			res = append(res, token{text: src[start:i], line: line})
+		case strings.ContainsRune("(),;.=&|^~", rune(ch)):
+			res = append(res, token{text: string(ch), line: line})
+			i++
+		case ch == '_' || ch == '\'' || unicode.IsLetter(rune(ch)) || unicode.IsDigit(rune(ch)):
+			start := i
+			for i < len(src) && (src[i] == '_' || src[i] == '$' || src[i] == '\'' || unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i]))) {
+				i++
+			}
+			res = append(res, token{text: src[start:i], line: line})
+		default:
+			return nil, fmt.Errorf("line %d: unexpected character %q", line, ch)
+		}
+	}
+	return res, nil
+}
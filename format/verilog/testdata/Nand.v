@@ -0,0 +1,34 @@
+module NAND_a_b__1 (a_0, b_1, NAND_a_b__2);
+  input a_0;
+  input b_1;
+  output NAND_a_b__2;
+  wire Vcc_3;
+  assign Vcc_3 = 1'b1;
+  wire NAND_a_b__wire_4_gnd;
+  wire NAND_a_b__wire_4;
+  wire Gnd_5_gnd;
+  assign Gnd_5_gnd = 1'b1;
+  wire Gnd_5;
+  // transistor base a collector Vcc emitter NAND(a,b)-wire collectorOut NAND(a,b)
+  nmos (NAND_a_b__wire_4, Vcc_3, a_0);
+  pulldown (NAND_a_b__wire_4);
+  assign NAND_a_b__2 = Vcc_3 & ~(a_0 & NAND_a_b__wire_4_gnd);
+  // transistor base b collector NAND(a,b)-wire emitter Gnd collectorOut Unused
+  nmos (Gnd_5, NAND_a_b__wire_4, b_1);
+  pulldown (Gnd_5);
+  assign NAND_a_b__wire_4_gnd = NAND_a_b__wire_4 & b_1 & Gnd_5_gnd;
+endmodule
+
+module group_0 (a_0, b_1, NAND_a_b__2);
+  input a_0;
+  input b_1;
+  output NAND_a_b__2;
+  NAND_a_b__1 u0 (.a_0(a_0), .b_1(b_1), .NAND_a_b__2(NAND_a_b__2));
+endmodule
+
+module top (a_0, b_1, NAND_a_b__2);
+  input a_0;
+  input b_1;
+  output NAND_a_b__2;
+  group_0 u0 (.a_0(a_0), .b_1(b_1), .NAND_a_b__2(NAND_a_b__2));
+endmodule
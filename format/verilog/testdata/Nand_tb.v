@@ -0,0 +1,63 @@
+module testbench;
+  reg a_0;
+  reg b_1;
+  wire NAND_a_b__2;
+  integer errors;
+  top dut (.a_0(a_0), .b_1(b_1), .NAND_a_b__2(NAND_a_b__2));
+  initial begin
+    errors = 0;
+    a_0 = 1'b0;
+    b_1 = 1'b0;
+    force dut.NAND_a_b__2 = 1'b1;
+    force dut.u0.u0.Vcc_3 = 1'b1;
+    force dut.u0.u0.NAND_a_b__wire_4_gnd = 1'b0;
+    force dut.u0.u0.NAND_a_b__wire_4 = 1'b0;
+    force dut.u0.u0.Gnd_5_gnd = 1'b1;
+    force dut.u0.u0.Gnd_5 = 1'b0;
+    #1;
+    release dut.NAND_a_b__2;
+    release dut.u0.u0.Vcc_3;
+    release dut.u0.u0.NAND_a_b__wire_4_gnd;
+    release dut.u0.u0.NAND_a_b__wire_4;
+    release dut.u0.u0.Gnd_5_gnd;
+    release dut.u0.u0.Gnd_5;
+    // 00=>1
+    a_0 = 1'b0;
+    b_1 = 1'b0;
+    #1;
+    $display("%b%b=>%b", a_0, b_1, NAND_a_b__2);
+    if ({NAND_a_b__2} !== 1'b1) begin
+      $display("mismatch: want 00=>1");
+      errors = errors + 1;
+    end
+    // 01=>1
+    a_0 = 1'b0;
+    b_1 = 1'b1;
+    #1;
+    $display("%b%b=>%b", a_0, b_1, NAND_a_b__2);
+    if ({NAND_a_b__2} !== 1'b1) begin
+      $display("mismatch: want 01=>1");
+      errors = errors + 1;
+    end
+    // 10=>1
+    a_0 = 1'b1;
+    b_1 = 1'b0;
+    #1;
+    $display("%b%b=>%b", a_0, b_1, NAND_a_b__2);
+    if ({NAND_a_b__2} !== 1'b1) begin
+      $display("mismatch: want 10=>1");
+      errors = errors + 1;
+    end
+    // 11=>0
+    a_0 = 1'b1;
+    b_1 = 1'b1;
+    #1;
+    $display("%b%b=>%b", a_0, b_1, NAND_a_b__2);
+    if ({NAND_a_b__2} !== 1'b0) begin
+      $display("mismatch: want 11=>0");
+      errors = errors + 1;
+    end
+    if (errors == 0) $display("PASS"); else $display("FAIL: %0d mismatches", errors);
+    $finish;
+  end
+endmodule
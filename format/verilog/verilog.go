@@ -0,0 +1,470 @@
+// Package verilog exports circuits as structural Verilog.
+package verilog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kssilveira/circuit-engine/circuit"
+	"github.com/kssilveira/circuit-engine/component"
+	"github.com/kssilveira/circuit-engine/group"
+	"github.com/kssilveira/circuit-engine/jointwire"
+	"github.com/kssilveira/circuit-engine/netlist"
+	"github.com/kssilveira/circuit-engine/sfmt"
+	"github.com/kssilveira/circuit-engine/transistor"
+	"github.com/kssilveira/circuit-engine/wire"
+)
+
+// Top is the name of the top module.
+const Top = "top"
+
+var (
+	// gates maps group kinds to Verilog gate primitives.
+	gates = map[string]string{
+		"NOT":  "not",
+		"AND":  "and",
+		"OR":   "or",
+		"NAND": "nand",
+		"NOR":  "nor",
+		"XOR":  "xor",
+	}
+)
+
+// Export returns the circuit as structural Verilog.
+//
+// Each group becomes a module and the circuit becomes the top module.
+// Transistors become NMOS switches from collector to emitter with a pulldown on the emitter,
+// plus assignments for the collector output and for the ground seen through the emitter.
+// Joint wires become OR and AND primitives.
+// With Config.GateLevel, groups built by lib/gate become gate primitives.
+func Export(c *circuit.Circuit) (string, error) {
+	d, err := newDesign(c)
+	if err != nil {
+		return "", err
+	}
+	return d.String(), nil
+}
+
+// Testbench returns a testbench for the top module that replays the given input vectors.
+//
+// It simulates the circuit to compute the expected outputs, starting from the state after the
+// first vector, which the testbench forces on every net before replaying the vectors.
+func Testbench(c *circuit.Circuit, vectors []string) (string, error) {
+	d, err := newDesign(c)
+	if err != nil {
+		return "", err
+	}
+	var inputs, outputs []*signal
+	for _, input := range c.Inputs {
+		inputs = append(inputs, d.signals[key{w: input}])
+	}
+	for _, output := range c.Outputs {
+		outputs = append(outputs, d.signals[key{w: output}])
+	}
+	res := []string{
+		"module testbench;",
+	}
+	for _, input := range inputs {
+		res = append(res, sfmt.Sprintf("  reg %s;", input.name))
+	}
+	for _, port := range d.top.ports {
+		if !port.isInput {
+			res = append(res, sfmt.Sprintf("  wire %s;", port.name))
+		}
+	}
+	res = append(res, "  integer errors;")
+	res = append(res, sfmt.Sprintf("  %s dut (%s);", Top, connections(d.top.ports)))
+	res = append(res, "  initial begin", "    errors = 0;")
+	if len(vectors) > 0 {
+		c.SetInputs(vectors[0])
+		c.Update()
+	}
+	var forces, releases []string
+	for _, s := range d.order {
+		if s.isInput {
+			continue
+		}
+		path := s.home.path()
+		forces = append(forces, sfmt.Sprintf("    force %s.%s = %s;", path, s.name, literal(s.value())))
+		releases = append(releases, sfmt.Sprintf("    release %s.%s;", path, s.name))
+	}
+	res = append(res, assignInputs(inputs, vectors...)...)
+	res = append(res, forces...)
+	res = append(res, "    #1;")
+	res = append(res, releases...)
+	var format, args, got []string
+	for _, input := range inputs {
+		format = append(format, "%b")
+		args = append(args, input.name)
+	}
+	format = append(format, "=>")
+	for _, output := range outputs {
+		format = append(format, "%b")
+		args = append(args, output.name)
+		got = append(got, output.name)
+	}
+	for _, vector := range vectors {
+		c.SetInputs(vector)
+		if !c.IsValid() {
+			continue
+		}
+		c.Update()
+		want := c.StringForUnitTest()
+		res = append(res, sfmt.Sprintf("    // %s", want))
+		res = append(res, assignInputs(inputs, vector)...)
+		res = append(res, "    #1;")
+		res = append(res, sfmt.Sprintf(`    $display("%s", %s);`, strings.Join(format, ""), strings.Join(args, ", ")))
+		if len(got) > 0 {
+			wantOutputs := want[strings.Index(want, "=>")+len("=>"):]
+			res = append(res,
+				sfmt.Sprintf("    if ({%s} !== %d'b%s) begin", strings.Join(got, ", "), len(got), wantOutputs),
+				sfmt.Sprintf(`      $display("mismatch: want %s");`, want),
+				"      errors = errors + 1;",
+				"    end")
+		}
+	}
+	res = append(res,
+		`    if (errors == 0) $display("PASS"); else $display("FAIL: %0d mismatches", errors);`,
+		"    $finish;",
+		"  end",
+		"endmodule",
+		"")
+	return strings.Join(res, "\n"), nil
+}
+
+func assignInputs(inputs []*signal, vectors ...string) []string {
+	if len(vectors) == 0 {
+		return nil
+	}
+	var res []string
+	for i, value := range vectors[0] {
+		if i >= len(inputs) {
+			break
+		}
+		res = append(res, sfmt.Sprintf("    %s = 1'b%c;", inputs[i].name, value))
+	}
+	return res
+}
+
+type key struct {
+	w   *wire.Wire
+	gnd bool
+}
+
+type signal struct {
+	key      key
+	name     string
+	driver   *module
+	users    []*module
+	home     *module
+	isInput  bool
+	isOutput bool
+}
+
+func (s *signal) value() bool {
+	if s.key.gnd {
+		return s.key.w.Gnd.SilentGet()
+	}
+	return s.key.w.Bit.SilentGet()
+}
+
+type module struct {
+	name     string
+	instance string
+	parent   *module
+	depth    int
+	children []*module
+	lines    []string
+	ports    []*signal
+	isOutput map[*signal]bool
+}
+
+func (m *module) path() string {
+	if m.parent == nil {
+		return "dut"
+	}
+	return m.parent.path() + "." + m.instance
+}
+
+type design struct {
+	gateLevel bool
+	nets      *netlist.Netlist
+	gndRead   map[*wire.Wire]bool
+	signals   map[key]*signal
+	order     []*signal
+	top       *module
+	modules   []*module
+	count     int
+}
+
+func newDesign(c *circuit.Circuit) (*design, error) {
+	d := &design{
+		gateLevel: c.Config.GateLevel,
+		nets:      netlist.New(c),
+		gndRead:   map[*wire.Wire]bool{},
+		signals:   map[key]*signal{},
+		top:       &module{name: Top, isOutput: map[*signal]bool{}},
+	}
+	netlist.Walk(c.Components, func(component component.Component) {
+		if t, ok := component.(*transistor.Transistor); ok {
+			d.gndRead[t.Emitter] = true
+		}
+	})
+	for _, input := range c.Inputs {
+		d.signal(input, false).isInput = true
+	}
+	for _, output := range c.Outputs {
+		d.signal(output, false).isOutput = true
+	}
+	if err := d.addComponents(d.top, c.Components); err != nil {
+		return nil, err
+	}
+	d.modules = append(d.modules, d.top)
+	for _, s := range d.order {
+		d.place(s)
+	}
+	return d, nil
+}
+
+func (d *design) signal(w *wire.Wire, gnd bool) *signal {
+	k := key{w: w, gnd: gnd}
+	if res, ok := d.signals[k]; ok {
+		return res
+	}
+	index, _ := d.nets.Index(w)
+	name := sfmt.Sprintf("%s_%d", identifier(w.Name), index)
+	if gnd {
+		name += "_gnd"
+	}
+	res := &signal{key: k, name: name}
+	d.signals[k] = res
+	d.order = append(d.order, res)
+	return res
+}
+
+func (d *design) use(m *module, w *wire.Wire, gnd bool) string {
+	s := d.signal(w, gnd)
+	s.users = append(s.users, m)
+	return s.name
+}
+
+func (d *design) drive(m *module, w *wire.Wire, gnd bool) (string, error) {
+	s := d.signal(w, gnd)
+	if s.driver != nil {
+		return "", fmt.Errorf("multiple drivers for %q", w.Name)
+	}
+	s.driver = m
+	return s.name, nil
+}
+
+func (d *design) addComponents(m *module, components []component.Component) error {
+	for _, one := range components {
+		var err error
+		switch one := one.(type) {
+		case *group.Group:
+			err = d.addGroup(m, one)
+		case *transistor.Transistor:
+			err = d.addTransistor(m, one)
+		case *jointwire.JointWire:
+			err = d.addJointWire(m, one)
+		default:
+			err = fmt.Errorf("unsupported component %T", one)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *design) addGroup(parent *module, g *group.Group) error {
+	if primitive, ok := gates[g.Kind]; ok && d.gateLevel {
+		var ports []string
+		for _, out := range g.Outs {
+			name, err := d.drive(parent, out, false)
+			if err != nil {
+				return err
+			}
+			ports = append(ports, name)
+		}
+		for _, in := range g.Ins {
+			ports = append(ports, d.use(parent, in, false))
+		}
+		parent.lines = append(parent.lines,
+			sfmt.Sprintf("  // %s", g.Name),
+			sfmt.Sprintf("  %s (%s);", primitive, strings.Join(ports, ", ")))
+		return nil
+	}
+	name := g.Name
+	if name == "" {
+		name = "group"
+	}
+	m := &module{
+		name:     sfmt.Sprintf("%s_%d", identifier(name), d.count),
+		instance: sfmt.Sprintf("u%d", len(parent.children)),
+		parent:   parent,
+		depth:    parent.depth + 1,
+		isOutput: map[*signal]bool{},
+	}
+	d.count++
+	parent.children = append(parent.children, m)
+	if err := d.addComponents(m, g.Components); err != nil {
+		return err
+	}
+	d.modules = append(d.modules, m)
+	return nil
+}
+
+func (d *design) addTransistor(m *module, t *transistor.Transistor) error {
+	b := d.use(m, t.Base, false)
+	c := d.use(m, t.Collector, false)
+	eGnd := d.use(m, t.Emitter, true)
+	e, err := d.drive(m, t.Emitter, false)
+	if err != nil {
+		return err
+	}
+	m.lines = append(m.lines,
+		sfmt.Sprintf("  // transistor base %s collector %s emitter %s collectorOut %s", t.Base.Name, t.Collector.Name, t.Emitter.Name, t.CollectorOut.Name),
+		sfmt.Sprintf("  nmos (%s, %s, %s);", e, c, b),
+		sfmt.Sprintf("  pulldown (%s);", e))
+	if t.CollectorOut.Name != "Unused" {
+		co, err := d.drive(m, t.CollectorOut, false)
+		if err != nil {
+			return err
+		}
+		m.lines = append(m.lines, sfmt.Sprintf("  assign %s = %s & ~(%s & %s);", co, c, b, eGnd))
+	}
+	if d.gndRead[t.Collector] {
+		cGnd, err := d.drive(m, t.Collector, true)
+		if err != nil {
+			return err
+		}
+		m.lines = append(m.lines, sfmt.Sprintf("  assign %s = %s & %s & %s;", cGnd, c, b, eGnd))
+	}
+	return nil
+}
+
+func (d *design) addJointWire(m *module, w *jointwire.JointWire) error {
+	a := d.use(m, w.A, false)
+	b := d.use(m, w.B, false)
+	res, err := d.drive(m, w.Res, false)
+	if err != nil {
+		return err
+	}
+	primitive := "or"
+	if w.IsAnd {
+		primitive = "and"
+	}
+	m.lines = append(m.lines, sfmt.Sprintf("  %s (%s, %s, %s);", primitive, res, a, b))
+	return nil
+}
+
+// place finds the module declaring the signal and adds the ports needed to reach it.
+func (d *design) place(s *signal) {
+	var all []*module
+	if s.driver != nil {
+		all = append(all, s.driver)
+	}
+	all = append(all, s.users...)
+	if s.isInput || s.isOutput || len(all) == 0 {
+		s.home = d.top
+	} else {
+		s.home = all[0]
+		for _, m := range all[1:] {
+			s.home = lca(s.home, m)
+		}
+	}
+	if s.isInput || s.isOutput {
+		d.top.ports = append(d.top.ports, s)
+		d.top.isOutput[s] = !s.isInput
+	}
+	for i, m := range all {
+		for ; m != s.home; m = m.parent {
+			if _, ok := m.isOutput[s]; !ok {
+				m.ports = append(m.ports, s)
+				m.isOutput[s] = i == 0 && s.driver != nil
+			}
+		}
+	}
+}
+
+func lca(a, b *module) *module {
+	for a.depth > b.depth {
+		a = a.parent
+	}
+	for b.depth > a.depth {
+		b = b.parent
+	}
+	for a != b {
+		a, b = a.parent, b.parent
+	}
+	return a
+}
+
+func (d *design) String() string {
+	var res []string
+	for _, m := range d.modules {
+		res = append(res, d.moduleString(m))
+	}
+	return strings.Join(res, "\n")
+}
+
+func (d *design) moduleString(m *module) string {
+	var names []string
+	for _, port := range m.ports {
+		names = append(names, port.name)
+	}
+	res := []string{sfmt.Sprintf("module %s (%s);", m.name, strings.Join(names, ", "))}
+	for _, port := range m.ports {
+		direction := "input"
+		if m.isOutput[port] {
+			direction = "output"
+		}
+		res = append(res, sfmt.Sprintf("  %s %s;", direction, port.name))
+	}
+	for _, s := range d.order {
+		if s.home != m {
+			continue
+		}
+		if _, ok := m.isOutput[s]; !ok {
+			res = append(res, sfmt.Sprintf("  wire %s;", s.name))
+		}
+		if s.driver == nil && !s.isInput {
+			res = append(res, sfmt.Sprintf("  assign %s = %s;", s.name, literal(s.value())))
+		}
+	}
+	res = append(res, m.lines...)
+	for _, child := range m.children {
+		res = append(res, sfmt.Sprintf("  %s %s (%s);", child.name, child.instance, connections(child.ports)))
+	}
+	res = append(res, "endmodule", "")
+	return strings.Join(res, "\n")
+}
+
+func connections(ports []*signal) string {
+	var res []string
+	for _, port := range ports {
+		res = append(res, sfmt.Sprintf(".%s(%s)", port.name, port.name))
+	}
+	return strings.Join(res, ", ")
+}
+
+func literal(v bool) string {
+	return "1'b" + wire.BoolToString(v)
+}
+
+// identifier converts a name to a Verilog identifier.
+func identifier(name string) string {
+	var res strings.Builder
+	for _, r := range name {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			res.WriteRune(r)
+		} else {
+			res.WriteRune('_')
+		}
+	}
+	if res.Len() == 0 || (name[0] >= '0' && name[0] <= '9') {
+		return "n" + res.String()
+	}
+	return res.String()
+}
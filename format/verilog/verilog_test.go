@@ -0,0 +1,87 @@
+package verilog
+
+import (
+	"flag"
+	"slices"
+	"testing"
+
+	"github.com/kssilveira/circuit-engine/circuit"
+	"github.com/kssilveira/circuit-engine/config"
+	"github.com/kssilveira/circuit-engine/golden"
+	"github.com/kssilveira/circuit-engine/lib"
+)
+
+var update = flag.Bool("update", false, "write the golden files instead of comparing with them")
+
+func TestImport(t *testing.T) {
+	src := `
+// full adder
+module half (input a, input b, output s, output c);
+  assign s = a ^ b;
+  and g0 (c, a, b);
+endmodule
+
+module full (a, b, cin, s, cout);
+  input a, b, cin;
+  output s, cout;
+  wire s1, c1, c2;
+  half h0 (.a(a), .b(b), .s(s1), .c(c1));
+  half h1 (s1, cin, s, c2);
+  assign cout = ~(~c1 & ~c2);
+endmodule
+`
+	c := circuit.NewCircuit(config.Config{IsUnitTest: true})
+	if err := Import(c, src, ""); err != nil {
+		t.Fatalf("Import got err %v", err)
+	}
+	want := []string{
+		"000=>00", "001=>10", "010=>10", "011=>01",
+		"100=>10", "101=>01", "110=>01", "111=>11",
+	}
+	if got := c.Simulate(); !slices.Equal(want, got) {
+		t.Errorf("Simulate want %q got %q", want, got)
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	for _, name := range []string{"Not", "And", "Or", "Nand", "Nor", "Xor", "Nand(Nand)", "HalfSum", "Sum", "Sum2", "SumN", "Bus2", "BusBnIOn"} {
+		cfg := config.Config{IsUnitTest: true, GateLevel: true}
+		c := circuit.NewCircuit(cfg)
+		c.Outs(lib.Example(c, name))
+		src, err := Export(c)
+		if err != nil {
+			t.Fatalf("Export(%q) got err %v", name, err)
+		}
+		imported := circuit.NewCircuit(cfg)
+		if err := Import(imported, src, Top); err != nil {
+			t.Fatalf("Import(Export(%q)) got err %v", name, err)
+		}
+		if want, got := c.Simulate(), imported.Simulate(); !slices.Equal(want, got) {
+			t.Errorf("Simulate(Import(Export(%q))) want %q got %q", name, want, got)
+		}
+	}
+}
+
+func TestExportGolden(t *testing.T) {
+	for _, in := range []struct {
+		file   string
+		export func(c *circuit.Circuit) (string, error)
+	}{{
+		file:   "testdata/Nand.v",
+		export: Export,
+	}, {
+		// the testbench forces the state after the first vector before replaying them
+		file: "testdata/Nand_tb.v",
+		export: func(c *circuit.Circuit) (string, error) {
+			return Testbench(c, []string{"00", "01", "10", "11"})
+		},
+	}} {
+		c := circuit.NewCircuit(config.Config{IsUnitTest: true})
+		c.Outs(lib.Example(c, "Nand"))
+		got, err := in.export(c)
+		if err != nil {
+			t.Fatalf("export %s got err %v", in.file, err)
+		}
+		golden.Check(t, in.file, got, *update)
+	}
+}
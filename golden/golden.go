@@ -0,0 +1,128 @@
+// Package golden compares test results with committed golden files.
+//
+// Tests that use it define an -update flag, which writes the golden files instead, and review them with git diff:
+//
+//	go test ./lib -update
+package golden
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kssilveira/circuit-engine/circuit"
+	"github.com/kssilveira/circuit-engine/sfmt"
+)
+
+// Context is the number of unchanged lines shown around each change in a diff.
+const Context = 2
+
+// Check compares the result with the golden file, or writes the file if update is set.
+func Check(t testing.TB, path, got string, update bool) {
+	t.Helper()
+	if update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("MkdirAll got err %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatalf("WriteFile got err %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Errorf("ReadFile got err %v, run with -update to create it", err)
+		return
+	}
+	if diff := Diff(string(want), got); diff != "" {
+		t.Errorf("%s changed, run with -update if expected (- want, + got):\n%s", path, diff)
+	}
+}
+
+// Trace returns the description of the circuit and one line per result of circuit.SimulateInputs, such as
+//
+//	a(1) b(0) => S(a,b)(1) C(a,b)(0)
+//
+// The circuit should be simulated with Config.IsUnitTest.
+func Trace(c *circuit.Circuit, results []string) string {
+	res := []string{c.Description(), ""}
+	for _, out := range results {
+		var one []string
+		for i, input := range c.Inputs {
+			one = append(one, sfmt.Sprintf("%s(%s)", input.Name, string(out[i])))
+		}
+		one = append(one, "=>")
+		for i, output := range c.Outputs {
+			one = append(one, sfmt.Sprintf("%s(%s)", output.Name, string(out[i+len(c.Inputs)+len("=>")])))
+		}
+		res = append(res, strings.Join(one, " "))
+	}
+	res = append(res, "")
+	return strings.Join(res, "\n")
+}
+
+// Diff returns the changed lines, prefixed by "-" when only in want and "+" when only in got,
+// with Context unchanged lines around them, or "" if there are no changes.
+func Diff(want, got string) string {
+	a, b := strings.Split(want, "\n"), strings.Split(got, "\n")
+	// common[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+	type line struct {
+		op   byte
+		text string
+		// want and got are the 1-based line numbers
+		want, got int
+	}
+	var lines []line
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, line{' ', a[i], i + 1, j + 1})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || common[i+1][j] >= common[i][j+1]):
+			lines = append(lines, line{'-', a[i], i + 1, j + 1})
+			i++
+		default:
+			lines = append(lines, line{'+', b[j], i + 1, j + 1})
+			j++
+		}
+	}
+	// each hunk contains the changed lines and their context, merging the ones that touch
+	var hunks [][2]int
+	for k, one := range lines {
+		if one.op == ' ' {
+			continue
+		}
+		start, end := max(k-Context, 0), min(k+Context, len(lines)-1)
+		if len(hunks) > 0 && start <= hunks[len(hunks)-1][1]+1 {
+			hunks[len(hunks)-1][1] = end
+			continue
+		}
+		hunks = append(hunks, [2]int{start, end})
+	}
+	var res []string
+	for _, hunk := range hunks {
+		first := lines[hunk[0]]
+		res = append(res, fmt.Sprintf("@@ want line %d, got line %d @@", first.want, first.got))
+		for _, one := range lines[hunk[0] : hunk[1]+1] {
+			res = append(res, string(one.op)+one.text)
+		}
+	}
+	return strings.Join(res, "\n")
+}
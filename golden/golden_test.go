@@ -0,0 +1,43 @@
+package golden
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	lines := func(from, to int) []string {
+		var res []string
+		for i := from; i <= to; i++ {
+			res = append(res, string(rune('a'+i)))
+		}
+		return res
+	}
+	want := strings.Join(lines(0, 9), "\n")
+	inputs := []struct {
+		name string
+		got  string
+		want string
+	}{{
+		name: "equal",
+		got:  want,
+		want: "",
+	}, {
+		name: "changed",
+		got:  strings.Replace(want, "e", "E", 1),
+		want: "@@ want line 3, got line 3 @@\n c\n d\n-e\n+E\n f\n g",
+	}, {
+		name: "added at the end",
+		got:  want + "\nk",
+		want: "@@ want line 9, got line 9 @@\n i\n j\n+k",
+	}, {
+		name: "two hunks",
+		got:  strings.Join(append([]string{"A"}, lines(1, 8)...), "\n"),
+		want: "@@ want line 1, got line 1 @@\n-a\n+A\n b\n c\n@@ want line 8, got line 8 @@\n h\n i\n-j",
+	}}
+	for _, in := range inputs {
+		if got := Diff(want, in.got); got != in.want {
+			t.Errorf("Diff(%s) want\n%s\ngot\n%s", in.name, in.want, got)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Package graphid assigns stable graphviz IDs.
+package graphid
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// IDs assigns graphviz IDs that depend on where components are in the groups that contain them,
+// so that they are stable across runs and adding a component does not change the IDs of unrelated ones.
+type IDs struct {
+	ids  map[any]string
+	used map[string]bool
+	// scope contains the indexes of the current component in the enclosing groups.
+	scope []string
+}
+
+// New creates an empty set of IDs.
+func New() *IDs {
+	return &IDs{ids: map[any]string{}, used: map[string]bool{}}
+}
+
+// Enter starts the scope of the i-th component of a group or circuit, which Leave ends.
+func (ids *IDs) Enter(i int) {
+	ids.scope = append(ids.scope, strconv.Itoa(i))
+}
+
+// Leave ends the scope started by the last Enter.
+func (ids *IDs) Leave() {
+	ids.scope = ids.scope[:len(ids.scope)-1]
+}
+
+// ID returns the ID of the given component pointer and whether it is new, made of the prefix and the
+// indexes of the current scope, such as t0_2_1 for the second component of the third component of the first one.
+func (ids *IDs) ID(prefix string, key any) (string, bool) {
+	return ids.add(key, prefix+strings.Join(ids.scope, "_"))
+}
+
+// Wire returns the ID of the given wire pointer and whether it is new, made of the wire name and the scope
+// of the component that uses it first, such as w0_2/a, with a counter for other wires with the same name there.
+func (ids *IDs) Wire(name string, key any) (string, bool) {
+	return ids.add(key, fmt.Sprintf("w%s/%s", strings.Join(ids.scope, "_"), strings.ReplaceAll(name, `"`, "'")))
+}
+
+func (ids *IDs) add(key any, id string) (string, bool) {
+	if id, ok := ids.ids[key]; ok {
+		return id, false
+	}
+	res := id
+	for i := 1; ids.used[res]; i++ {
+		res = fmt.Sprintf("%s#%d", id, i)
+	}
+	ids.used[res] = true
+	ids.ids[key] = res
+	return res, true
+}
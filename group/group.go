@@ -2,11 +2,17 @@
 package group
 
 import (
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/kssilveira/circuit-engine/behavior"
 	"github.com/kssilveira/circuit-engine/component"
 	"github.com/kssilveira/circuit-engine/config"
 	"github.com/kssilveira/circuit-engine/draw"
+	"github.com/kssilveira/circuit-engine/graphid"
 	"github.com/kssilveira/circuit-engine/jointwire"
 	"github.com/kssilveira/circuit-engine/sfmt"
 	"github.com/kssilveira/circuit-engine/transistor"
@@ -17,15 +23,145 @@ import (
 type Group struct {
 	Name       string
 	Components []component.Component
+	// Kind identifies groups built by a library function, such as "AND".
+	Kind string
+	// Ins and Outs contain the ports of groups with a Kind.
+	Ins  []*wire.Wire
+	Outs []*wire.Wire
+	// path contains the names of the enclosing named groups and this one separated by "/", as used by Find without indexes.
+	path string
+	// models is shared by the nested groups.
+	models     *models
+	behavioral bool
+}
+
+// models contains the patterns of config.Config.Behavioral and config.Config.Transistors.
+type models struct {
+	behavioral  []string
+	transistors []string
+}
+
+// Gates contains the kinds of the groups built by lib/gate.
+var Gates = []string{"NOT", "AND", "OR", "NAND", "NOR", "XOR"}
+
+// PortNames contains the names of the gate inputs, and the gate output is named "out".
+var PortNames = []string{"a", "b"}
+
+// New creates a top-level group, which selects behavioral models with cfg.Behavioral and cfg.Transistors.
+func New(name string, cfg config.Config) *Group {
+	parent := &Group{models: &models{behavioral: cfg.Behavioral, transistors: cfg.Transistors}}
+	return parent.Group(name)
 }
 
 // Group creates a new group.
 func (g *Group) Group(name string) *Group {
-	res := &Group{Name: name}
+	res := &Group{Name: name, path: g.path, models: g.models, behavioral: g.behavioral}
+	if name != "" {
+		res.path = path.Join(g.path, name)
+		res.selectModels()
+	}
 	g.Components = append(g.Components, res)
 	return res
 }
 
+// selectModels sets whether the group uses behavioral models, which nested groups inherit unless their paths match other patterns.
+func (g *Group) selectModels() {
+	if g.models == nil {
+		return
+	}
+	match := func(patterns []string) bool {
+		return slices.ContainsFunc(patterns, func(pattern string) bool {
+			ok, _ := path.Match(pattern, g.path)
+			return ok
+		})
+	}
+	if match(g.models.behavioral) {
+		g.behavioral = true
+	}
+	if match(g.models.transistors) {
+		g.behavioral = false
+	}
+}
+
+// Behavioral returns whether library functions should build the group as a behavioral model instead of transistors.
+func (g *Group) Behavioral() bool {
+	return g.behavioral
+}
+
+// Behavior adds a behavioral block with the given ports, named after the kind or the name of the group.
+func (g *Group) Behavior(ins, outs []*wire.Wire, eval func(ins, outs []bool) []bool) {
+	name := g.Kind
+	if name == "" {
+		name = g.Name
+	}
+	g.Components = append(g.Components, &behavior.Block{Name: name, Ins: ins, Outs: outs, Eval: eval})
+}
+
+// SetKind sets the kind and ports of the group.
+func (g *Group) SetKind(kind string, ins, outs []*wire.Wire) {
+	g.Kind = kind
+	g.Ins = ins
+	g.Outs = outs
+}
+
+// IsGate returns whether the group is a gate built by lib/gate.
+func (g *Group) IsGate() bool {
+	return slices.Contains(Gates, g.Kind)
+}
+
+// Find returns the group at the given path, which contains the names of the nested groups separated by "/".
+//
+// Unnamed groups are skipped, and "name#k" selects the k-th group with the same name, starting at 1.
+func (g *Group) Find(path string) (*Group, error) {
+	res := g
+	for _, name := range strings.Split(path, "/") {
+		index := 1
+		if i := strings.LastIndex(name, "#"); i >= 0 {
+			k, err := strconv.Atoi(name[i+1:])
+			if err != nil || k < 1 {
+				return nil, fmt.Errorf("invalid index in %q", name)
+			}
+			name, index = name[:i], k
+		}
+		var found *Group
+		var names []string
+		for _, child := range res.named() {
+			if !slices.Contains(names, child.Name) {
+				names = append(names, child.Name)
+			}
+			if child.Name == name {
+				index--
+				if index == 0 {
+					found = child
+					break
+				}
+			}
+		}
+		if found == nil {
+			return nil, fmt.Errorf("group %q not found in %q, valid names are %q", name, res.Name, names)
+		}
+		res = found
+	}
+	return res, nil
+}
+
+// named returns the nested groups with a name, skipping unnamed groups.
+func (g *Group) named() []*Group {
+	var res []*Group
+	for _, component := range g.Components {
+		child, ok := component.(*Group)
+		if !ok {
+			continue
+		}
+		if child.Name == "" {
+			res = append(res, child.named()...)
+			continue
+		}
+		res = append(res, child)
+	}
+	return res
+}
+
 // Update updates all components.
 func (g *Group) Update(updateReaders bool) {
 	for _, component := range g.Components {
@@ -102,17 +238,106 @@ var (
 	horizontalLine = strings.Repeat("-", 10)
 )
 
-func (g Group) String(depth int, cfg config.Config) string {
-	if cfg.MaxPrintDepth >= 0 && depth >= cfg.MaxPrintDepth {
-		return ""
+// Ports returns the wires that cross the group boundary.
+//
+// Groups with a Kind return Ins and Outs. Otherwise, the inputs are the wires read inside and not
+// written inside, and the outputs are the wires written inside and either read outside or in the given
+// circuit outputs, so dangling wires are not ports. Readers are only known after the first update,
+// and constants are not ports.
+func (g *Group) Ports(outputs []*wire.Wire) ([]*wire.Wire, []*wire.Wire) {
+	if g.Kind != "" {
+		return g.Ins, g.Outs
+	}
+	inside := map[component.Component]bool{}
+	read, written := map[*wire.Wire]bool{}, map[*wire.Wire]bool{}
+	var reads, writes []*wire.Wire
+	add := func(set map[*wire.Wire]bool, list *[]*wire.Wire, wires ...*wire.Wire) {
+		for _, w := range wires {
+			if !set[w] && !isConstant(w) {
+				set[w] = true
+				*list = append(*list, w)
+			}
+		}
+	}
+	var walk func(components []component.Component)
+	walk = func(components []component.Component) {
+		for _, one := range components {
+			inside[one] = true
+			switch one := one.(type) {
+			case *Group:
+				walk(one.Components)
+			case *transistor.Transistor:
+				add(read, &reads, one.Base, one.Collector)
+				add(written, &writes, one.Emitter, one.CollectorOut)
+			case *jointwire.JointWire:
+				add(read, &reads, one.A, one.B)
+				add(written, &writes, one.Res)
+			case *behavior.Block:
+				add(read, &reads, one.Ins...)
+				add(written, &writes, one.Outs...)
+			}
+		}
+	}
+	walk(g.Components)
+	var ins, outs []*wire.Wire
+	for _, w := range reads {
+		if !written[w] {
+			ins = append(ins, w)
+		}
+	}
+	for _, w := range writes {
+		outside := slices.Contains(outputs, w)
+		for _, reader := range append(append([]component.Component{}, w.Bit.Readers()...), w.Gnd.Readers()...) {
+			outside = outside || !inside[reader]
+		}
+		if outside {
+			outs = append(outs, w)
+		}
 	}
+	return ins, outs
+}
+
+func isConstant(w *wire.Wire) bool {
+	return slices.Contains([]string{"Vcc", "Gnd", "T", "F", "Unused"}, w.Name)
+}
+
+func (g Group) String(depth int, cfg config.Config) string {
+	return g.StringOutputs(depth, cfg, nil)
+}
+
+// StringOutputs returns the group like String, with the given circuit outputs as ports of the collapsed groups, see Ports.
+func (g Group) StringOutputs(depth int, cfg config.Config, outputs []*wire.Wire) string {
 	prefix := draw.StringPrefix(depth)
+	collapsed := cfg.MaxPrintDepth >= 0 && depth >= cfg.MaxPrintDepth
+	if collapsed || (cfg.GateLevel && g.IsGate()) {
+		ins, outs := g.Ports(outputs)
+		label := g.Name
+		if !collapsed {
+			label = g.Kind
+		}
+		var res []string
+		for _, wire := range ins {
+			res = append(res, sfmt.Sprintf("%v", *wire))
+		}
+		if collapsed {
+			res = append(res, "=>")
+		}
+		for _, wire := range outs {
+			res = append(res, sfmt.Sprintf("%v", *wire))
+		}
+		return sfmt.Sprintf("%s%s %s", prefix, label, strings.Join(res, "    "))
+	}
 	res := []string{
 		prefix + g.Name,
 		prefix + horizontalLine,
 	}
 	for _, component := range g.Components {
-		one := component.String(depth+1, cfg)
+		var one string
+		if group, ok := component.(*Group); ok {
+			one = group.StringOutputs(depth+1, cfg, outputs)
+		} else {
+			one = component.String(depth+1, cfg)
+		}
 		if one == "" {
 			continue
 		}
@@ -123,20 +348,36 @@ func (g Group) String(depth int, cfg config.Config) string {
 }
 
 // Graph returns a graphviz graph.
-func (g Group) Graph(depth int, cfg config.Config) string {
-	if cfg.MaxPrintDepth >= 0 && depth >= cfg.MaxPrintDepth {
-		return ""
-	}
+func (g *Group) Graph(depth int, cfg config.Config, ids *graphid.IDs) string {
+	return g.GraphOutputs(depth, cfg, ids, nil)
+}
+
+// GraphOutputs returns a graphviz graph like Graph, with the given circuit outputs as ports of the collapsed groups, see Ports.
+func (g *Group) GraphOutputs(depth int, cfg config.Config, ids *graphid.IDs, outputs []*wire.Wire) string {
 	prefix := draw.GraphPrefix(depth)
 	nextPrefix := draw.GraphPrefix(depth + 1)
+	id, _ := ids.ID("g", g)
+	if cfg.MaxPrintDepth >= 0 && depth >= cfg.MaxPrintDepth {
+		return g.boxGraph(prefix, id, cfg, ids, outputs)
+	}
+	if cfg.GateLevel && g.IsGate() {
+		return g.gateGraph(prefix, id, cfg, ids)
+	}
 	res := []string{
-		sfmt.Sprintf("%ssubgraph cluster_%p {", prefix, &g),
+		sfmt.Sprintf("%ssubgraph cluster_%s {", prefix, id),
 		sfmt.Sprintf(`%slabel="%s";`, nextPrefix, g.Name),
 		sfmt.Sprintf(`%sgraph[style=dotted];`, nextPrefix),
-		sfmt.Sprintf(`%s"%p"[style=invis,shape=point];`, nextPrefix, &g),
+		sfmt.Sprintf(`%s"%s"[style=invis,shape=point];`, nextPrefix, id),
 	}
-	for _, component := range g.Components {
-		one := component.Graph(depth+1, cfg)
+	for i, component := range g.Components {
+		ids.Enter(i)
+		var one string
+		if group, ok := component.(*Group); ok {
+			one = group.GraphOutputs(depth+1, cfg, ids, outputs)
+		} else {
+			one = component.Graph(depth+1, cfg, ids)
+		}
+		ids.Leave()
 		if one == "" {
 			continue
 		}
@@ -145,3 +386,68 @@ func (g Group) Graph(depth int, cfg config.Config) string {
 	res = append(res, sfmt.Sprintf("%s}", prefix))
 	return strings.Join(res, "\n")
 }
+
+// port is a port of a record node.
+type port struct {
+	id    string
+	label string
+	wire  *wire.Wire
+}
+
+// gateGraph returns the gate as a record node with the named ports, or nothing without Config.DrawNodes like transistors.
+func (g *Group) gateGraph(prefix, id string, cfg config.Config, ids *graphid.IDs) string {
+	if !cfg.DrawNodes {
+		return ""
+	}
+	var ins []port
+	for i, wire := range g.Ins {
+		ins = append(ins, port{PortNames[i], PortNames[i], wire})
+	}
+	return recordGraph(prefix, id, g.Kind, ins, []port{{"out", "out", g.Outs[0]}}, cfg, ids)
+}
+
+// boxGraph returns the collapsed group as a record node with a port for each wire that crosses its boundary.
+func (g *Group) boxGraph(prefix, id string, cfg config.Config, ids *graphid.IDs, outputs []*wire.Wire) string {
+	wires, outWires := g.Ports(outputs)
+	var ins, outs []port
+	for i, wire := range wires {
+		ins = append(ins, port{sfmt.Sprintf("i%d", i), sfmt.Sprintf("%v", *wire), wire})
+	}
+	for i, wire := range outWires {
+		outs = append(outs, port{sfmt.Sprintf("o%d", i), sfmt.Sprintf("%v", *wire), wire})
+	}
+	return recordGraph(prefix, id, g.Name, ins, outs, cfg, ids)
+}
+
+// recordGraph returns a record node with the input ports on the left and the output ports on the right.
+func recordGraph(prefix, id, label string, ins, outs []port, cfg config.Config, ids *graphid.IDs) string {
+	fields := func(ports []port) string {
+		var res []string
+		for _, port := range ports {
+			res = append(res, sfmt.Sprintf("<%s>%s", port.id, draw.RecordEscape(port.label)))
+		}
+		return "{" + strings.Join(res, "|") + "}"
+	}
+	res := []string{
+		sfmt.Sprintf(`%s"%s" [shape=record;label="%s|%s|%s"];`, prefix, id, fields(ins), draw.RecordEscape(label), fields(outs)),
+	}
+	for _, port := range ins {
+		wireID, node := draw.WireNode(ids, port.wire, cfg.DrawShapePoint)
+		if node != "" {
+			res = append(res, prefix+node)
+		}
+		if cfg.DrawEdges {
+			res = append(res, sfmt.Sprintf(`%s"%s" -> "%s":%s %s;`, prefix, wireID, id, port.id, draw.EdgeColor(port.wire, port.wire)))
+		}
+	}
+	for _, port := range outs {
+		wireID, node := draw.WireNode(ids, port.wire, cfg.DrawShapePoint)
+		if node != "" {
+			res = append(res, prefix+node)
+		}
+		if cfg.DrawEdges {
+			res = append(res, sfmt.Sprintf(`%s"%s":%s -> "%s" %s;`, prefix, id, port.id, wireID, draw.EdgeColor(port.wire, port.wire)))
+		}
+	}
+	return strings.Join(res, "\n")
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/kssilveira/circuit-engine/config"
 	"github.com/kssilveira/circuit-engine/draw"
+	"github.com/kssilveira/circuit-engine/graphid"
 	"github.com/kssilveira/circuit-engine/sfmt"
 	"github.com/kssilveira/circuit-engine/wire"
 )
@@ -44,21 +45,23 @@ func (w JointWire) String(depth int, _ config.Config) string {
 }
 
 // Graph returns the graphviz graph.
-func (w JointWire) Graph(depth int, cfg config.Config) string {
+func (w *JointWire) Graph(depth int, cfg config.Config, ids *graphid.IDs) string {
 	if !cfg.DrawNodes {
 		return ""
 	}
 	prefix := draw.GraphPrefix(depth)
 	var res []string
-	if cfg.DrawShapePoint {
-		res = append(res, sfmt.Sprintf(`%s"%v" [label= "";shape=point];`, prefix, *w.Res))
+	resID, node := draw.WireNode(ids, w.Res, cfg.DrawShapePoint)
+	if node != "" {
+		res = append(res, prefix+node)
 	}
 	for _, wire := range []*wire.Wire{w.A, w.B} {
-		if cfg.DrawShapePoint {
-			res = append(res, sfmt.Sprintf(`%s"%v" [label= "";shape=point];`, prefix, *wire))
+		wireID, node := draw.WireNode(ids, wire, cfg.DrawShapePoint)
+		if node != "" {
+			res = append(res, prefix+node)
 		}
 		if cfg.DrawEdges {
-			res = append(res, sfmt.Sprintf(`%s"%v" -> "%v" %s;`, prefix, *wire, *w.Res, draw.EdgeColor(wire, w.Res)))
+			res = append(res, sfmt.Sprintf(`%s"%s" -> "%s" %s;`, prefix, wireID, resID, draw.EdgeColor(wire, w.Res)))
 		}
 	}
 	return strings.Join(res, "\n")
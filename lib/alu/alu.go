@@ -2,13 +2,13 @@
 package alu
 
 import (
+	"math/bits"
 	"slices"
 
 	"github.com/kssilveira/circuit-engine/group"
 	"github.com/kssilveira/circuit-engine/lib/bus"
 	"github.com/kssilveira/circuit-engine/lib/decode"
 	"github.com/kssilveira/circuit-engine/lib/gate"
-	"github.com/kssilveira/circuit-engine/lib/latch"
 	"github.com/kssilveira/circuit-engine/lib/ram"
 	"github.com/kssilveira/circuit-engine/lib/reg"
 	"github.com/kssilveira/circuit-engine/lib/sum"
@@ -128,93 +128,224 @@ func WithRAMInputValidation(ai, bi, ri, ro, mai, _, mo *wire.Wire) func() bool {
 	}
 }
 
-// WithCPU adds an arithmetic logic unit with CPU.
+// OpcodeBits is the number of bits of the opcodes of the CPU added by WithCPU.
+const OpcodeBits = 4
+
+// Instruction is an instruction of the CPU added by WithCPU.
+//
+// Its words contain the opcode in the high OpcodeBits bits and the operand, an address or value, in the low bits.
+type Instruction struct {
+	Mnemonic string
+	Opcode   int
+	// Operand is set for instructions that use the low bits of their word.
+	Operand bool
+	// Steps contains the control lines set by each microcode step after the fetch steps.
+	Steps [][]string
+}
+
+// Instructions contains the instruction set of the CPU added by WithCPU, where the missing opcodes do nothing.
+var Instructions = []Instruction{
+	{"NOP", 0b0000, false, nil},
+	{"LDA", 0b0001, true, [][]string{{"io", "mi"}, {"ro", "ai"}}},
+	{"ADD", 0b0010, true, [][]string{{"io", "mi"}, {"ro", "bi"}, {"ti"}, {"to", "ai"}}},
+	{"SUB", 0b0011, true, [][]string{{"io", "mi"}, {"ro", "bi"}, {"ti", "su"}, {"to", "ai"}}},
+	{"STA", 0b0100, true, [][]string{{"io", "mi"}, {"ao", "ri"}}},
+	{"LDI", 0b0101, true, [][]string{{"io", "ai"}}},
+	{"JMP", 0b0110, true, [][]string{{"io", "ci"}}},
+	{"OUT", 0b1110, false, [][]string{{"ao", "oi"}}},
+	{"HLT", 0b1111, false, [][]string{{"ht"}}},
+}
+
+// Control is a control line of the CPU added by WithCPU.
+type Control struct {
+	Name string
+	Doc  string
+}
+
+// Controls contains the control lines of the CPU added by WithCPU.
+var Controls = []Control{
+	{"co", "program counter out"},
+	{"mi", "memory address register in"},
+	{"ro", "RAM out"},
+	{"ii", "instruction register in"},
+	{"ce", "program counter increment"},
+	{"ci", "program counter in"},
+	{"io", "instruction register operand out"},
+	{"ai", "A register in"},
+	{"ao", "A register out"},
+	{"bi", "B register in"},
+	{"ti", "total register in"},
+	{"to", "total register out"},
+	{"su", "subtract B instead of adding it"},
+	{"ri", "RAM in"},
+	{"oi", "output register in"},
+	{"ht", "halt the step counter"},
+	{"sr", "reset the step counter"},
+}
+
+// fetch contains the control lines set by the steps that load the next instruction.
+var fetch = [][]string{{"co", "mi"}, {"ro", "ii", "ce"}}
+
+// microcode returns the control lines set by each step of the instruction with the given opcode,
+// including the fetch steps, with sr at the last step unless it halts.
+func microcode(opcode int) [][]string {
+	steps := [][]string{nil}
+	for _, one := range Instructions {
+		if one.Opcode == opcode && len(one.Steps) > 0 {
+			steps = one.Steps
+		}
+	}
+	res := slices.Concat(fetch, steps)
+	last := len(res) - 1
+	if !slices.Contains(res[last], "ht") {
+		res[last] = append(slices.Clone(res[last]), "sr")
+	}
+	return res
+}
+
+// WithCPU adds an arithmetic logic unit with CPU running the instructions in Instructions,
+// with n address bits and words of OpcodeBits+n bits.
+//
+// Its registers load from the bus on the rising edge of e, and its microcode step advances on the falling edge,
+// so the control lines are stable while the registers load.
 func WithCPU(parent *group.Group, e *wire.Wire, n int) []*wire.Wire {
 	group := parent.Group("CPU")
+	width := OpcodeBits + n
+	var codes [][][]string
+	steps := 0
+	for opcode := 0; opcode < 1<<OpcodeBits; opcode++ {
+		codes = append(codes, microcode(opcode))
+		steps = max(steps, len(codes[opcode]))
+	}
 
-	// step counter for microcode
-	s := latch.CounterN(group, e, n)
-
-	// current step
-	sel := decode.Decode(group, s)
-	for i := range sel {
-		sel[i] = gate.And(group, sel[i], gate.Not(group, e))
-	}
-
-	// step 0: program counter out (co), memory address in (mi)
-	co := gate.Or(group, sel[0], group.False())
-	co.Name = "co"
-	mi := gate.Or(group, sel[0], sel[2])
-	mi.Name = "mi"
-
-	// step 1: ram out (ro), instruction register in (ii), program counter increment (ce)
-	ro := gate.Or(group, sel[1], sel[3])
-	ro.Name = "ro"
-	ii := gate.Or(group, sel[1], group.False())
-	ii.Name = "ii"
-	ce := gate.Or(group, sel[1], group.False())
-	ce.Name = "ce"
-
-	// step 2: program counter in (ci), instruction out (io)
-	ci := gate.Or(group, sel[2], group.False())
-	ci.Name = "ci"
-	io := gate.Or(group, sel[2], group.False())
-	io.Name = "io"
-
-	// step 3: a register in (ai), ram memory out (ro, above)
-	ai := gate.Or(group, sel[3], group.False())
-	ai.Name = "ai"
-
-	// b register in
-	bi := &wire.Wire{Name: "bi"}
-	// ram in
-	ri := &wire.Wire{Name: "ri"}
-	// total register in
-	ti := &wire.Wire{Name: "ti"}
-	// total register out
-	to := &wire.Wire{Name: "to"}
-
-	var a, b, d, i, m, r []*wire.Wire
-	for bit := 0; bit < n; bit++ {
-		// a register
-		a = append(a, &wire.Wire{Name: "a"})
-		// b register
-		b = append(b, &wire.Wire{Name: "b"})
-		// bus data
-		d = append(d, &wire.Wire{Name: "d"})
-		// instruction register
-		i = append(i, &wire.Wire{Name: "i"})
-		// memory address register
-		m = append(m, &wire.Wire{Name: "m"})
-		// ram output
-		r = append(r, &wire.Wire{Name: "r"})
-	}
-
-	// a register
-	ar := reg.N(group, a, ai, group.True())
-	// b register
-	br := reg.N(group, b, bi, group.True())
-	// total
-	t := sum.N(group, ar, br, group.False())
-	// carry out
-	last := len(t) - 1
-	t[last].Name = sfmt.Sprintf("C(%s,%s)", a[last-1].Name, b[last-1].Name)
-	// total register
-	tr := reg.N(group, t[:last], ti, to)
-	for i, ai := range a {
-		tr[i].Name = sfmt.Sprintf("RS%s%s", ai.Name, b[i].Name)
+	named := func(name string, n int) []*wire.Wire {
+		var res []*wire.Wire
+		for i := 0; i < n; i++ {
+			res = append(res, &wire.Wire{Name: sfmt.Sprintf("%s%d", name, i)})
+		}
+		return res
+	}
+	bus := named("bus", width)
+	pc := named("pc", n)
+	step := named("step", bits.Len(uint(steps-1)))
+	controls := map[string]*wire.Wire{}
+	for _, one := range Controls {
+		controls[one.Name] = &wire.Wire{Name: one.Name}
+	}
+	ctrl := func(name string) *wire.Wire {
+		return controls[name]
+	}
+	falses := func(n int) []*wire.Wire {
+		var res []*wire.Wire
+		for i := 0; i < n; i++ {
+			res = append(res, group.False())
+		}
+		return res
 	}
 
-	// program counter register
-	cr := reg.N(group, latch.CounterN(group, ce, n), ci, co)
-	// instruction register
-	ir := reg.N(group, i, ii, io)
+	// step counter, first so it stops loading before the other registers change
+	ne := gate.Not(group, e)
+	inc := sum.N(group, step, falses(len(step)), group.True())
+	var next []*wire.Wire
+	for i := range step {
+		next = append(next, gate.And(group, inc[i], gate.Not(group, ctrl("sr"))))
+	}
+	reg.FlipFlopNRes(group, "STEP", step, next, gate.Not(group, ctrl("ht")), ne)
+
+	// instruction register, with the opcode in the high bits
+	ir := reg.FlipFlopN(group, "IR", bus, ctrl("ii"), e)
 	// memory address register
-	mr := reg.N(group, m, mi, group.True())
-	// ram output
-	rr := ram.RAM(group, mr, r, ri, ro)
-	// bus data
-	dr := bus.BnIOn(group, append([][]*wire.Wire{d, cr, ir}, rr...), [][]*wire.Wire{a, b, i, m, r})
+	mar := reg.FlipFlopN(group, "MAR", bus[:n], ctrl("mi"), e)
+	// program counter, which loads the bus or increments
+	inc = sum.N(group, pc, falses(n), group.True())
+	next = nil
+	for i := range pc {
+		next = append(next, gate.Or(group, gate.And(group, inc[i], gate.Not(group, ctrl("ci"))), gate.And(group, bus[i], ctrl("ci"))))
+	}
+	reg.FlipFlopNRes(group, "PC", pc, next, gate.Or(group, ctrl("ce"), ctrl("ci")), e)
+	// a and b registers
+	a := reg.FlipFlopN(group, "A", bus, ctrl("ai"), e)
+	b := reg.FlipFlopN(group, "B", bus, ctrl("bi"), e)
+	// total register, with a+b, or a-b as a plus the two's complement of b
+	var sb []*wire.Wire
+	for _, bi := range b {
+		sb = append(sb, gate.Xor(group, bi, ctrl("su")))
+	}
+	t := reg.FlipFlopN(group, "T", sum.N(group, a, sb, ctrl("su"))[:width], ctrl("ti"), e)
+	// output register
+	out := reg.FlipFlopN(group, "OUT", bus, ctrl("oi"), e)
+
+	// control lines, from the current step and opcode
+	op := decode.Decode(group, ir[n:])
+	for i, one := range op {
+		one.Name = sfmt.Sprintf("op%d", i)
+	}
+	sel := decode.Decode(group, step)
+	for i, one := range sel {
+		one.Name = sfmt.Sprintf("T%d", i)
+	}
+	for _, one := range Controls {
+		var terms []*wire.Wire
+		for k := range steps {
+			var ops []*wire.Wire
+			for opcode, code := range codes {
+				if k < len(code) && slices.Contains(code[k], one.Name) {
+					ops = append(ops, op[opcode])
+				}
+			}
+			switch {
+			case len(ops) == 0:
+			case k < len(fetch):
+				terms = append(terms, sel[k])
+			default:
+				terms = append(terms, gate.And(group, sel[k], or(group, ops)))
+			}
+		}
+		orRes(group, ctrl(one.Name), terms)
+	}
+
+	// ram, written while e is set
+	mem := ram.RAM(group, mar, bus, gate.And(group, ctrl("ri"), e), ctrl("ro"))
 
-	return slices.Concat(ar, br, cr, dr, s, tr, ir, mr, slices.Concat(rr...))
+	// bus, driven by the registers with their out control lines set
+	for i := range bus {
+		var terms []*wire.Wire
+		if i < n {
+			terms = append(terms, gate.And(group, ctrl("co"), pc[i]), gate.And(group, ctrl("io"), ir[i]))
+		}
+		terms = append(terms, gate.And(group, ctrl("ao"), a[i]), gate.And(group, ctrl("to"), t[i]))
+		for _, word := range mem {
+			terms = append(terms, word[i])
+		}
+		orRes(group, bus[i], terms)
+	}
+
+	var lines []*wire.Wire
+	for _, one := range Controls {
+		lines = append(lines, ctrl(one.Name))
+	}
+	return slices.Concat(bus, pc, step, ir, mar, a, b, t, out, lines)
+}
+
+// or adds OR gates for the wires, which must not be empty.
+func or(g *group.Group, w []*wire.Wire) *wire.Wire {
+	res := w[0]
+	for _, one := range w[1:] {
+		res = gate.Or(g, res, one)
+	}
+	return res
+}
+
+// orRes adds OR gates for the wires using the result parameter.
+func orRes(g *group.Group, res *wire.Wire, w []*wire.Wire) {
+	name := res.Name
+	switch len(w) {
+	case 0:
+		gate.OrRes(g, res, g.False(), g.False())
+	case 1:
+		gate.OrRes(g, res, w[0], g.False())
+	default:
+		gate.OrRes(g, res, or(g, w[:len(w)-1]), w[len(w)-1])
+	}
+	res.Name = name
 }
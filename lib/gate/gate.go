@@ -38,6 +38,13 @@ func Transistor(parent *group.Group, base, collector *wire.Wire) []*wire.Wire {
 func Not(parent *group.Group, a *wire.Wire) *wire.Wire {
 	group := parent.Group(sfmt.Sprintf("NOT(%s)", a.Name))
 	res := &wire.Wire{Name: group.Name}
+	group.SetKind("NOT", []*wire.Wire{a}, []*wire.Wire{res})
+	if group.Behavioral() {
+		group.Behavior(group.Ins, group.Outs, func(ins, _ []bool) []bool {
+			return []bool{!ins[0]}
+		})
+		return res
+	}
 	group.Transistor(a, group.Vcc(), group.Gnd(), res)
 	return res
 }
@@ -46,6 +53,13 @@ func Not(parent *group.Group, a *wire.Wire) *wire.Wire {
 func And(parent *group.Group, a, b *wire.Wire) *wire.Wire {
 	group := parent.Group(sfmt.Sprintf("AND(%s,%s)", a.Name, b.Name))
 	res := &wire.Wire{Name: group.Name}
+	group.SetKind("AND", []*wire.Wire{a, b}, []*wire.Wire{res})
+	if group.Behavioral() {
+		group.Behavior(group.Ins, group.Outs, func(ins, _ []bool) []bool {
+			return []bool{ins[0] && ins[1]}
+		})
+		return res
+	}
 	wire := &wire.Wire{Name: sfmt.Sprintf("%s-wire", res.Name)}
 	group.AddTransistors([]*transistor.Transistor{
 		{Base: a, Collector: group.Vcc(), Emitter: wire},
@@ -64,6 +78,13 @@ func Or(parent *group.Group, a, b *wire.Wire) *wire.Wire {
 func OrRes(parent *group.Group, res, a, b *wire.Wire) *wire.Wire {
 	group := parent.Group(sfmt.Sprintf("OR(%s,%s)", a.Name, b.Name))
 	res.Name = group.Name
+	group.SetKind("OR", []*wire.Wire{a, b}, []*wire.Wire{res})
+	if group.Behavioral() {
+		group.Behavior(group.Ins, group.Outs, func(ins, _ []bool) []bool {
+			return []bool{ins[0] || ins[1]}
+		})
+		return res
+	}
 	wire1 := &wire.Wire{Name: sfmt.Sprintf("%s-wire1", res.Name)}
 	wire2 := &wire.Wire{Name: sfmt.Sprintf("%s-wire2", res.Name)}
 	group.AddTransistors([]*transistor.Transistor{
@@ -78,6 +99,13 @@ func OrRes(parent *group.Group, res, a, b *wire.Wire) *wire.Wire {
 func Nand(parent *group.Group, a, b *wire.Wire) *wire.Wire {
 	group := parent.Group(sfmt.Sprintf("NAND(%s,%s)", a.Name, b.Name))
 	res := &wire.Wire{Name: group.Name}
+	group.SetKind("NAND", []*wire.Wire{a, b}, []*wire.Wire{res})
+	if group.Behavioral() {
+		group.Behavior(group.Ins, group.Outs, func(ins, _ []bool) []bool {
+			return []bool{!(ins[0] && ins[1])}
+		})
+		return res
+	}
 	wire := &wire.Wire{Name: sfmt.Sprintf("%s-wire", res.Name)}
 	group.AddTransistors([]*transistor.Transistor{
 		{Base: a, Collector: group.Vcc(), Emitter: wire, CollectorOut: res},
@@ -89,8 +117,17 @@ func Nand(parent *group.Group, a, b *wire.Wire) *wire.Wire {
 // Xor adds a XOR gate.
 func Xor(parent *group.Group, a, b *wire.Wire) *wire.Wire {
 	group := parent.Group(sfmt.Sprintf("XOR(%s,%s)", a.Name, b.Name))
+	if group.Behavioral() {
+		res := &wire.Wire{Name: group.Name}
+		group.SetKind("XOR", []*wire.Wire{a, b}, []*wire.Wire{res})
+		group.Behavior(group.Ins, group.Outs, func(ins, _ []bool) []bool {
+			return []bool{ins[0] != ins[1]}
+		})
+		return res
+	}
 	res := And(group, Or(group, a, b), Nand(group, a, b))
 	res.Name = group.Name
+	group.SetKind("XOR", []*wire.Wire{a, b}, []*wire.Wire{res})
 	return res
 }
 
@@ -104,6 +141,13 @@ func Nor(parent *group.Group, a, b *wire.Wire) *wire.Wire {
 func NorRes(parent *group.Group, res, a, b *wire.Wire) *wire.Wire {
 	group := parent.Group(sfmt.Sprintf("NOR(%s,%s)", a.Name, b.Name))
 	res.Name = group.Name
+	group.SetKind("NOR", []*wire.Wire{a, b}, []*wire.Wire{res})
+	if group.Behavioral() {
+		group.Behavior(group.Ins, group.Outs, func(ins, _ []bool) []bool {
+			return []bool{!(ins[0] || ins[1])}
+		})
+		return res
+	}
 	wire1 := &wire.Wire{Name: sfmt.Sprintf("%s-wire1", res.Name)}
 	wire2 := &wire.Wire{Name: sfmt.Sprintf("%s-wire2", res.Name)}
 	group.AddTransistors([]*transistor.Transistor{
@@ -48,7 +48,25 @@ func DLatch(parent *group.Group, d, e *wire.Wire) []*wire.Wire {
 // DLatchRes adds a data latch using the result parameter.
 func DLatchRes(parent *group.Group, q, d, e *wire.Wire) []*wire.Wire {
 	group := parent.Group(sfmt.Sprintf("DLATCH(%s,%s,%s)", d.Name, e.Name, q.Name))
-	return SRLatchResWithEnable(group, q, d, gate.Not(group, d), e)
+	res := SRLatchResWithEnable(group, q, d, gate.Not(group, d), e)
+	group.SetKind("DLATCH", []*wire.Wire{d, e}, res)
+	return res
+}
+
+// DFlipFlop adds a data flip-flop triggered by the rising edge of the enable wire.
+func DFlipFlop(parent *group.Group, d, e *wire.Wire) []*wire.Wire {
+	q := &wire.Wire{Name: "q"}
+	return DFlipFlopRes(parent, q, d, e)
+}
+
+// DFlipFlopRes adds a data flip-flop triggered by the rising edge of the enable wire using the result parameter.
+func DFlipFlopRes(parent *group.Group, q, d, e *wire.Wire) []*wire.Wire {
+	group := parent.Group(sfmt.Sprintf("DFF(%s,%s,%s)", d.Name, e.Name, q.Name))
+	mq := &wire.Wire{Name: "m" + q.Name}
+	DLatchRes(group, mq, d, gate.Not(group, e))
+	res := DLatchRes(group, q, mq, e)
+	group.SetKind("DFF", []*wire.Wire{d, e}, res)
+	return res
 }
 
 // MSJKLatch adds a master-slave JK latch.
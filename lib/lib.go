@@ -11,28 +11,11 @@ import (
 	"github.com/kssilveira/circuit-engine/lib/latch"
 	"github.com/kssilveira/circuit-engine/lib/ram"
 	"github.com/kssilveira/circuit-engine/lib/reg"
+	"github.com/kssilveira/circuit-engine/lib/rom"
 	"github.com/kssilveira/circuit-engine/lib/sum"
 	"github.com/kssilveira/circuit-engine/wire"
 )
 
-// Example returns the example with the given name.
-func Example(c *circuit.Circuit, name string) []*wire.Wire {
-	res, ok := examples[name]
-	if !ok {
-		return nil
-	}
-	return res(c)
-}
-
-// ExampleNames returns the available example names.
-func ExampleNames() []string {
-	var res []string
-	for name := range examples {
-		res = append(res, name)
-	}
-	return res
-}
-
 // W creates a wire.
 func W(name string) *wire.Wire {
 	return &wire.Wire{Name: name}
@@ -43,199 +26,464 @@ func WS(ws ...*wire.Wire) []*wire.Wire {
 	return ws
 }
 
+// ports documents the inputs and outputs shared by several examples.
 var (
-	examples = map[string]func(*circuit.Circuit) []*wire.Wire{
-		"TransistorEmitter": func(c *circuit.Circuit) []*wire.Wire {
-			return gate.TransistorEmitter(c.Group(""), c.In("b"), c.In("c"))
-		},
-		"TransistorGnd": func(c *circuit.Circuit) []*wire.Wire {
-			return gate.TransistorGnd(c.Group(""), c.In("b"), c.In("c"))
-		},
-		"Transistor": func(c *circuit.Circuit) []*wire.Wire {
-			return gate.Transistor(c.Group(""), c.In("b"), c.In("c"))
-		},
-		"Not": func(c *circuit.Circuit) []*wire.Wire {
-			return WS(gate.Not(c.Group(""), c.In("a")))
-		},
-		"And": func(c *circuit.Circuit) []*wire.Wire {
-			return WS(gate.And(c.Group(""), c.In("a"), c.In("b")))
-		},
-		"Or": func(c *circuit.Circuit) []*wire.Wire {
-			return WS(gate.Or(c.Group(""), c.In("a"), c.In("b")))
-		},
-		"OrRes": func(c *circuit.Circuit) []*wire.Wire {
-			res := &wire.Wire{Name: "res"}
-			return WS(gate.OrRes(c.Group(""), res, c.In("a"), res))
-		},
-		"Nand": func(c *circuit.Circuit) []*wire.Wire {
-			return WS(gate.Nand(c.Group(""), c.In("a"), c.In("b")))
-		},
-		"Nand(Nand)": func(c *circuit.Circuit) []*wire.Wire {
-			g := c.Group("")
-			return WS(gate.Nand(g, c.In("a"), gate.Nand(g, c.In("b"), c.In("c"))))
-		},
-		"Xor": func(c *circuit.Circuit) []*wire.Wire {
-			return WS(gate.Xor(c.Group(""), c.In("a"), c.In("b")))
-		},
-		"Nor": func(c *circuit.Circuit) []*wire.Wire {
-			return WS(gate.Nor(c.Group(""), c.In("a"), c.In("b")))
-		},
-		"HalfSum": func(c *circuit.Circuit) []*wire.Wire {
-			return sum.HalfSum(c.Group(""), c.In("a"), c.In("b"))
-		},
-		"Sum": func(c *circuit.Circuit) []*wire.Wire {
-			return sum.Sum(c.Group(""), c.In("a"), c.In("b"), c.In("c"))
-		},
-		"Sum2": func(c *circuit.Circuit) []*wire.Wire {
-			return sum.Sum2(c.Group(""), c.In("a0"), c.In("a1"), c.In("b0"), c.In("b1"), c.In("c"))
-		},
-		"SumN": func(c *circuit.Circuit) []*wire.Wire {
-			return sum.N(c.Group(""), WS(c.In("a0"), c.In("a1")), WS(c.In("b0"), c.In("b1")), c.In("c"))
-		},
-		"SRLatch": func(c *circuit.Circuit) []*wire.Wire {
-			return latch.SRLatch(c.Group(""), c.In("s"), c.In("r"))
-		},
-		"SRLatchWithEnable": func(c *circuit.Circuit) []*wire.Wire {
-			return latch.SRLatchWithEnable(c.Group(""), c.In("s"), c.In("r"), c.In("e"))
-		},
-		"DLatch": func(c *circuit.Circuit) []*wire.Wire {
-			return latch.DLatch(c.Group(""), c.In("d"), c.In("e"))
-		},
-		"MSJKLatch": func(c *circuit.Circuit) []*wire.Wire {
-			return latch.MSJKLatch(c.Group(""), c.In("j"), c.In("k"), c.In("e"))
-		},
-		"Counter": func(c *circuit.Circuit) []*wire.Wire {
-			return latch.Counter(c.Group(""), c.In("e"))
-		},
-		"Counter2": func(c *circuit.Circuit) []*wire.Wire {
-			return latch.Counter2(c.Group(""), c.In("e"))
-		},
-		"CounterN": func(c *circuit.Circuit) []*wire.Wire {
-			return latch.CounterN(c.Group(""), c.In("e"), 2)
-		},
-		"Register": func(c *circuit.Circuit) []*wire.Wire {
-			return WS(reg.Register(c.Group(""), c.In("d"), c.In("i"), c.In("o")))
-		},
-		"Register2": func(c *circuit.Circuit) []*wire.Wire {
-			return reg.Register2(c.Group(""), c.In("d0"), c.In("d1"), c.In("i"), c.In("o"))
-		},
-		"RegisterN": func(c *circuit.Circuit) []*wire.Wire {
-			return reg.N(c.Group(""), WS(c.In("d0"), c.In("d1")), c.In("i"), c.In("o"))
-		},
-		"Alu": func(c *circuit.Circuit) []*wire.Wire {
-			return alu.Alu(
-				c.Group(""), c.In("a"), c.In("ai"), c.In("b"), c.In("bi"),
-				c.In("ri"), c.In("ro"), c.In("c"))
-		},
-		"Alu2": func(c *circuit.Circuit) []*wire.Wire {
-			return alu.Alu2(
-				c.Group(""), c.In("a0"), c.In("a1"), c.In("ai"), c.In("b0"), c.In("b1"), c.In("bi"),
-				c.In("ri"), c.In("ro"), c.In("c"))
-		},
-		"AluN": func(c *circuit.Circuit) []*wire.Wire {
-			return alu.N(
-				c.Group(""),
-				WS(c.In("a0"), c.In("a1")), c.In("ai"),
-				WS(c.In("b0"), c.In("b1")), c.In("bi"),
-				c.In("ri"), c.In("ro"), c.In("c"))
-		},
-		"Bus": func(c *circuit.Circuit) []*wire.Wire {
-			aw, bw := W("aw"), W("bw")
-			return append(bus.Bus(c.Group(""), c.In("d"), c.In("r"), aw, bw), aw, bw)
-		},
-		"Bus2": func(c *circuit.Circuit) []*wire.Wire {
-			aw0, aw1 := W("aw0"), W("aw1")
-			bw0, bw1 := W("bw0"), W("bw1")
-			return append(bus.Bus2(
-				c.Group(""), c.In("d0"), c.In("d1"), c.In("r0"), c.In("r1"),
-				aw0, aw1, bw0, bw1),
-				aw0, aw1, bw0, bw1)
-		},
-		"BusN": func(c *circuit.Circuit) []*wire.Wire {
-			aw0, aw1 := W("aw0"), W("aw1")
-			bw0, bw1 := W("bw0"), W("bw1")
-			return append(bus.N(
-				c.Group(""), WS(c.In("d0"), c.In("d1")), WS(c.In("r0"), c.In("r1")),
-				WS(aw0, aw1), WS(bw0, bw1)),
-				aw0, aw1, bw0, bw1)
-		},
-		"BusIOn": func(c *circuit.Circuit) []*wire.Wire {
-			aw, bw := W("aw"), W("bw")
-			return append(bus.IOn(c.Group(""), WS(c.In("d"), c.In("ar"), c.In("br"), c.In("r")), WS(aw, bw)), aw, bw)
-		},
-		"BusBnIOn": func(c *circuit.Circuit) []*wire.Wire {
-			aw0, aw1 := W("aw0"), W("aw1")
-			bw0, bw1 := W("bw0"), W("bw1")
-			return append(bus.BnIOn(
-				c.Group(""),
-				[][]*wire.Wire{{c.In("d0"), c.In("d1")}, {c.In("ar0"), c.In("ar1")}, {c.In("br0"), c.In("br1")}, {c.In("r0"), c.In("r1")}},
-				[][]*wire.Wire{{aw0, aw1}, {bw0, bw1}}),
-				aw0, aw1, bw0, bw1)
-		},
-		"AluWithBus": func(c *circuit.Circuit) []*wire.Wire {
-			d := c.In("d")
-			ai, bi := c.In("ai"), c.In("bi")
-			ri, ro := c.In("ri"), c.In("ro")
-			cin := c.In("c")
-			c.AddInputValidation(alu.WithBusInputValidation(ai, bi, ri, ro))
-			return alu.WithBus(c.Group(""), d, ai, bi, ri, ro, cin)
-		},
-		"AluWithBus2": func(c *circuit.Circuit) []*wire.Wire {
-			d0, d1 := c.In("d0"), c.In("d1")
-			ai, bi := c.In("ai"), c.In("bi")
-			ri, ro := c.In("ri"), c.In("ro")
-			cin := c.In("c")
-			c.AddInputValidation(alu.WithBusInputValidation(ai, bi, ri, ro))
-			return alu.WithBus2(c.Group(""), d0, d1, ai, bi, ri, ro, cin)
-		},
-		"AluWithBusN": func(c *circuit.Circuit) []*wire.Wire {
-			d0, d1 := c.In("d0"), c.In("d1")
-			ai, bi := c.In("ai"), c.In("bi")
-			ri, ro := c.In("ri"), c.In("ro")
-			cin := c.In("c")
-			c.AddInputValidation(alu.WithBusInputValidation(ai, bi, ri, ro))
-			return alu.WithBusN(c.Group(""), WS(d0, d1), ai, bi, ri, ro, cin)
-		},
-		"RAM": func(c *circuit.Circuit) []*wire.Wire {
-			return slices.Concat(ram.RAM(
-				c.Group(""), WS(c.In("a")), WS(c.In("d")), c.In("i"), c.In("o"))...)
-		},
-		"RAMa2": func(c *circuit.Circuit) []*wire.Wire {
-			return slices.Concat(ram.RAM(
-				c.Group(""), WS(c.In("a0"), c.In("a1")), WS(c.In("d")), c.In("i"), c.In("o"))...)
-		},
-		"RAMb2": func(c *circuit.Circuit) []*wire.Wire {
-			return slices.Concat(ram.RAM(
-				c.Group(""), WS(c.In("a")), WS(c.In("d0"), c.In("d1")), c.In("i"), c.In("o"))...)
-		},
-		"RAMa2b2": func(c *circuit.Circuit) []*wire.Wire {
-			return slices.Concat(ram.RAM(
-				c.Group(""), WS(c.In("a0"), c.In("a1")), WS(c.In("d0"), c.In("d1")),
-				c.In("i"), c.In("o"))...)
-		},
-		"AluWithRAM": func(c *circuit.Circuit) []*wire.Wire {
-			d := WS(c.In("d"))
-			ai, bi := c.In("ai"), c.In("bi")
-			ri, ro := c.In("ri"), c.In("ro")
-			cin := c.In("c")
-			mai, mi, mo := c.In("mai"), c.In("mi"), c.In("mo")
-			c.AddInputValidation(alu.WithRAMInputValidation(ai, bi, ri, ro, mai, mi, mo))
-			return alu.WithRAM(c.Group(""), d, ai, bi, ri, ro, cin, mai, mi, mo)
-		},
-		"AluWithRAM2": func(c *circuit.Circuit) []*wire.Wire {
-			d := WS(c.In("d0"), c.In("d1"))
-			ai, bi := c.In("ai"), c.In("bi")
-			ri, ro := c.In("ri"), c.In("ro")
-			cin := c.In("c")
-			mai, mi, mo := c.In("mai"), c.In("mi"), c.In("mo")
-			c.AddInputValidation(alu.WithRAMInputValidation(ai, bi, ri, ro, mai, mi, mo))
-			return alu.WithRAM(c.Group(""), d, ai, bi, ri, ro, cin, mai, mi, mo)
-		},
-		"AluWithCPU": func(c *circuit.Circuit) []*wire.Wire {
-			return alu.WithCPU(c.Group(""), c.In("e"), 2)
-		},
-		"": func(_ *circuit.Circuit) []*wire.Wire {
-			return nil
-		},
-	}
+	ab           = []Port{{"a", "first operand"}, {"b", "second operand"}}
+	transistor   = []Port{{"b", "base"}, {"c", "collector"}}
+	registerIO   = []Port{{"i", "load the data into the register"}, {"o", "drive the register output"}}
+	aluControl   = []Port{{"ai", "load the a register"}, {"bi", "load the b register"}, {"ri", "load the result register"}, {"ro", "drive the result register output"}, {"c", "carry in"}}
+	aluInputs    = []Port{{"a*", "first operand"}, {"ai", "load the a register"}, {"b*", "second operand"}, {"bi", "load the b register"}, {"ri", "load the result register"}, {"ro", "drive the result register output"}, {"c", "carry in"}}
+	aluOutputs   = []Port{{"R*", "a, b and result registers"}, {"C(*)", "carry out"}}
+	ramIO        = []Port{{"i", "write the data to the selected word"}, {"o", "drive the selected word output"}}
+	ramOutputs   = []Port{{"Rd*", "words, one register per address and bit"}}
+	busInputs    = []Port{{"d*", "data written to the bus"}, {"r*", "second writer of the bus"}}
+	busOutputs   = []Port{{"B*", "bus value"}, {"aw*", "first reader"}, {"bw*", "second reader"}}
+	latchOutputs = []Port{{"q", "state"}, {"nq", "inverted state"}}
 )
+
+func init() {
+	Register("TransistorEmitter", func(c *circuit.Circuit) []*wire.Wire {
+		return gate.TransistorEmitter(c.Group(""), c.In("b"), c.In("c"))
+	}, Meta{
+		Description: "transistor with only the emitter output",
+		Category:    Gate,
+		Inputs:      transistor,
+		Outputs:     []Port{{"e", "emitter"}},
+	})
+	Register("TransistorGnd", func(c *circuit.Circuit) []*wire.Wire {
+		return gate.TransistorGnd(c.Group(""), c.In("b"), c.In("c"))
+	}, Meta{
+		Description: "transistor with the emitter to ground and the collector output",
+		Category:    Gate,
+		Inputs:      transistor,
+		Outputs:     []Port{{"co", "collector output"}},
+	})
+	Register("Transistor", func(c *circuit.Circuit) []*wire.Wire {
+		return gate.Transistor(c.Group(""), c.In("b"), c.In("c"))
+	}, Meta{
+		Description: "transistor with the emitter and collector outputs",
+		Category:    Gate,
+		Inputs:      transistor,
+		Outputs:     []Port{{"e", "emitter"}, {"co", "collector output"}},
+	})
+	Register("Not", func(c *circuit.Circuit) []*wire.Wire {
+		return WS(gate.Not(c.Group(""), c.In("a")))
+	}, Meta{
+		Description: "NOT gate",
+		Category:    Gate,
+		Inputs:      []Port{{"a", "operand"}},
+		Outputs:     []Port{{"NOT(a)", "inverted operand"}},
+	})
+	Register("And", func(c *circuit.Circuit) []*wire.Wire {
+		return WS(gate.And(c.Group(""), c.In("a"), c.In("b")))
+	}, Meta{
+		Description: "AND gate",
+		Category:    Gate,
+		Inputs:      ab,
+		Outputs:     []Port{{"AND(a,b)", "a and b"}},
+	})
+	Register("Or", func(c *circuit.Circuit) []*wire.Wire {
+		return WS(gate.Or(c.Group(""), c.In("a"), c.In("b")))
+	}, Meta{
+		Description: "OR gate",
+		Category:    Gate,
+		Inputs:      ab,
+		Outputs:     []Port{{"OR(a,b)", "a or b"}},
+	})
+	Register("OrRes", func(c *circuit.Circuit) []*wire.Wire {
+		res := &wire.Wire{Name: "res"}
+		return WS(gate.OrRes(c.Group(""), res, c.In("a"), res))
+	}, Meta{
+		Description: "OR gate with its output fed back as an input, which stays set once a is set",
+		Category:    Gate,
+		Inputs:      []Port{{"a", "set the output"}},
+		Outputs:     []Port{{"OR(a,res)", "a or the previous output"}},
+		Sequential:  true,
+	})
+	Register("Nand", func(c *circuit.Circuit) []*wire.Wire {
+		return WS(gate.Nand(c.Group(""), c.In("a"), c.In("b")))
+	}, Meta{
+		Description: "NAND gate",
+		Category:    Gate,
+		Inputs:      ab,
+		Outputs:     []Port{{"NAND(a,b)", "not (a and b)"}},
+	})
+	Register("Nand(Nand)", func(c *circuit.Circuit) []*wire.Wire {
+		g := c.Group("")
+		return WS(gate.Nand(g, c.In("a"), gate.Nand(g, c.In("b"), c.In("c"))))
+	}, Meta{
+		Description: "NAND gate fed by another NAND gate",
+		Category:    Gate,
+		Inputs:      []Port{{"a", "first operand"}, {"b", "first operand of the inner gate"}, {"c", "second operand of the inner gate"}},
+		Outputs:     []Port{{"NAND(a,NAND(b,c))", "not (a and not (b and c))"}},
+	})
+	Register("Xor", func(c *circuit.Circuit) []*wire.Wire {
+		return WS(gate.Xor(c.Group(""), c.In("a"), c.In("b")))
+	}, Meta{
+		Description: "XOR gate",
+		Category:    Gate,
+		Inputs:      ab,
+		Outputs:     []Port{{"XOR(a,b)", "a different from b"}},
+	})
+	Register("Nor", func(c *circuit.Circuit) []*wire.Wire {
+		return WS(gate.Nor(c.Group(""), c.In("a"), c.In("b")))
+	}, Meta{
+		Description: "NOR gate",
+		Category:    Gate,
+		Inputs:      ab,
+		Outputs:     []Port{{"NOR(a,b)", "not (a or b)"}},
+	})
+	Register("HalfSum", func(c *circuit.Circuit) []*wire.Wire {
+		return sum.HalfSum(c.Group(""), c.In("a"), c.In("b"))
+	}, Meta{
+		Description: "half adder",
+		Category:    Arith,
+		Inputs:      ab,
+		Outputs:     []Port{{"S(a,b)", "sum"}, {"C(a,b)", "carry out"}},
+	})
+	Register("Sum", func(c *circuit.Circuit) []*wire.Wire {
+		return sum.Sum(c.Group(""), c.In("a"), c.In("b"), c.In("c"))
+	}, Meta{
+		Description: "full adder",
+		Category:    Arith,
+		Inputs:      append(slices.Clone(ab), Port{"c", "carry in"}),
+		Outputs:     []Port{{"S(a,b,c)", "sum"}, {"C(a,b)", "carry out"}},
+	})
+	sum2 := Meta{
+		Description: "2-bit adder",
+		Category:    Arith,
+		Inputs:      []Port{{"a*", "first operand, least significant bit first"}, {"b*", "second operand, least significant bit first"}, {"c", "carry in"}},
+		Outputs:     []Port{{"S(*)", "sum, least significant bit first"}, {"C(a1,b1)", "carry out"}},
+	}
+	Register("Sum2", func(c *circuit.Circuit) []*wire.Wire {
+		return sum.Sum2(c.Group(""), c.In("a0"), c.In("a1"), c.In("b0"), c.In("b1"), c.In("c"))
+	}, sum2)
+	sumN := sum2
+	sumN.Description = "N-bit adder with N=2"
+	Register("SumN", func(c *circuit.Circuit) []*wire.Wire {
+		return sum.N(c.Group(""), WS(c.In("a0"), c.In("a1")), WS(c.In("b0"), c.In("b1")), c.In("c"))
+	}, sumN)
+	Register("SRLatch", func(c *circuit.Circuit) []*wire.Wire {
+		return latch.SRLatch(c.Group(""), c.In("s"), c.In("r"))
+	}, Meta{
+		Description: "set-reset latch",
+		Category:    Memory,
+		Inputs:      []Port{{"s", "set"}, {"r", "reset"}},
+		Outputs:     latchOutputs,
+		Sequential:  true,
+	})
+	Register("SRLatchWithEnable", func(c *circuit.Circuit) []*wire.Wire {
+		return latch.SRLatchWithEnable(c.Group(""), c.In("s"), c.In("r"), c.In("e"))
+	}, Meta{
+		Description: "set-reset latch that only changes while enabled",
+		Category:    Memory,
+		Inputs:      []Port{{"s", "set"}, {"r", "reset"}, {"e", "enable"}},
+		Outputs:     latchOutputs,
+		Sequential:  true,
+	})
+	Register("DLatch", func(c *circuit.Circuit) []*wire.Wire {
+		return latch.DLatch(c.Group(""), c.In("d"), c.In("e"))
+	}, Meta{
+		Description: "data latch",
+		Category:    Memory,
+		Inputs:      []Port{{"d", "data"}, {"e", "enable, the state follows the data while set"}},
+		Outputs:     latchOutputs,
+		Sequential:  true,
+	})
+	Register("MSJKLatch", func(c *circuit.Circuit) []*wire.Wire {
+		return latch.MSJKLatch(c.Group(""), c.In("j"), c.In("k"), c.In("e"))
+	}, Meta{
+		Description: "master-slave JK latch",
+		Category:    Memory,
+		Inputs:      []Port{{"j", "set"}, {"k", "reset, toggles with j"}, {"e", "clock"}},
+		Outputs:     []Port{{"mq", "state"}, {"nmq", "inverted state"}},
+		Sequential:  true,
+	})
+	counter := Meta{
+		Description: "binary counter",
+		Category:    Memory,
+		Inputs:      []Port{{"e", "clock"}},
+		Outputs:     []Port{{"c", "count"}},
+		Sequential:  true,
+		Vectors:     Clock(9),
+	}
+	Register("Counter", func(c *circuit.Circuit) []*wire.Wire {
+		return latch.Counter(c.Group(""), c.In("e"))
+	}, counter)
+	counter.Description = "2-bit counter"
+	counter.Outputs = []Port{{"c*", "count, least significant bit first"}}
+	Register("Counter2", func(c *circuit.Circuit) []*wire.Wire {
+		return latch.Counter2(c.Group(""), c.In("e"))
+	}, counter)
+	counter.Description = "N-bit counter with N=2"
+	counter.Outputs = []Port{{"e*", "count, least significant bit first"}}
+	Register("CounterN", func(c *circuit.Circuit) []*wire.Wire {
+		return latch.CounterN(c.Group(""), c.In("e"), 2)
+	}, counter)
+	Register("Register", func(c *circuit.Circuit) []*wire.Wire {
+		return WS(reg.Register(c.Group(""), c.In("d"), c.In("i"), c.In("o")))
+	}, Meta{
+		Description: "register",
+		Category:    Memory,
+		Inputs:      append([]Port{{"d", "data"}}, registerIO...),
+		Outputs:     []Port{{"Rd", "register output"}},
+		Sequential:  true,
+	})
+	register2 := Meta{
+		Description: "2-bit register",
+		Category:    Memory,
+		Inputs:      append([]Port{{"d*", "data"}}, registerIO...),
+		Outputs:     []Port{{"Rd*", "register output"}},
+		Sequential:  true,
+	}
+	Register("Register2", func(c *circuit.Circuit) []*wire.Wire {
+		return reg.Register2(c.Group(""), c.In("d0"), c.In("d1"), c.In("i"), c.In("o"))
+	}, register2)
+	register2.Description = "N-bit register with N=2"
+	Register("RegisterN", func(c *circuit.Circuit) []*wire.Wire {
+		return reg.N(c.Group(""), WS(c.In("d0"), c.In("d1")), c.In("i"), c.In("o"))
+	}, register2)
+	Register("Alu", func(c *circuit.Circuit) []*wire.Wire {
+		return alu.Alu(
+			c.Group(""), c.In("a"), c.In("ai"), c.In("b"), c.In("bi"),
+			c.In("ri"), c.In("ro"), c.In("c"))
+	}, Meta{
+		Description: "adder with a, b and result registers",
+		Category:    Arith,
+		Inputs:      aluInputs,
+		Outputs:     aluOutputs,
+		Sequential:  true,
+	})
+	alu2 := Meta{
+		Description: "2-bit adder with a, b and result registers",
+		Category:    Arith,
+		Inputs:      aluInputs,
+		Outputs:     aluOutputs,
+		Sequential:  true,
+	}
+	Register("Alu2", func(c *circuit.Circuit) []*wire.Wire {
+		return alu.Alu2(
+			c.Group(""), c.In("a0"), c.In("a1"), c.In("ai"), c.In("b0"), c.In("b1"), c.In("bi"),
+			c.In("ri"), c.In("ro"), c.In("c"))
+	}, alu2)
+	alu2.Description = "N-bit adder with a, b and result registers with N=2"
+	Register("AluN", func(c *circuit.Circuit) []*wire.Wire {
+		return alu.N(
+			c.Group(""),
+			WS(c.In("a0"), c.In("a1")), c.In("ai"),
+			WS(c.In("b0"), c.In("b1")), c.In("bi"),
+			c.In("ri"), c.In("ro"), c.In("c"))
+	}, alu2)
+	Register("Bus", func(c *circuit.Circuit) []*wire.Wire {
+		aw, bw := W("aw"), W("bw")
+		return append(bus.Bus(c.Group(""), c.In("d"), c.In("r"), aw, bw), aw, bw)
+	}, Meta{
+		Description: "bus with two writers and two readers",
+		Category:    CPU,
+		Inputs:      busInputs,
+		Outputs:     busOutputs,
+	})
+	Register("Bus2", func(c *circuit.Circuit) []*wire.Wire {
+		aw0, aw1 := W("aw0"), W("aw1")
+		bw0, bw1 := W("bw0"), W("bw1")
+		return append(bus.Bus2(
+			c.Group(""), c.In("d0"), c.In("d1"), c.In("r0"), c.In("r1"),
+			aw0, aw1, bw0, bw1),
+			aw0, aw1, bw0, bw1)
+	}, Meta{
+		Description: "2-bit bus with two writers and two readers",
+		Category:    CPU,
+		Inputs:      busInputs,
+		Outputs:     busOutputs,
+	})
+	Register("BusN", func(c *circuit.Circuit) []*wire.Wire {
+		aw0, aw1 := W("aw0"), W("aw1")
+		bw0, bw1 := W("bw0"), W("bw1")
+		return append(bus.N(
+			c.Group(""), WS(c.In("d0"), c.In("d1")), WS(c.In("r0"), c.In("r1")),
+			WS(aw0, aw1), WS(bw0, bw1)),
+			aw0, aw1, bw0, bw1)
+	}, Meta{
+		Description: "N-bit bus with two writers and two readers with N=2",
+		Category:    CPU,
+		Inputs:      busInputs,
+		Outputs:     busOutputs,
+	})
+	Register("BusIOn", func(c *circuit.Circuit) []*wire.Wire {
+		aw, bw := W("aw"), W("bw")
+		return append(bus.IOn(c.Group(""), WS(c.In("d"), c.In("ar"), c.In("br"), c.In("r")), WS(aw, bw)), aw, bw)
+	}, Meta{
+		Description: "bus with four writers and two readers",
+		Category:    CPU,
+		Inputs:      []Port{{"d", "first writer"}, {"ar", "second writer"}, {"br", "third writer"}, {"r", "fourth writer"}},
+		Outputs:     busOutputs,
+	})
+	Register("BusBnIOn", func(c *circuit.Circuit) []*wire.Wire {
+		aw0, aw1 := W("aw0"), W("aw1")
+		bw0, bw1 := W("bw0"), W("bw1")
+		return append(bus.BnIOn(
+			c.Group(""),
+			[][]*wire.Wire{{c.In("d0"), c.In("d1")}, {c.In("ar0"), c.In("ar1")}, {c.In("br0"), c.In("br1")}, {c.In("r0"), c.In("r1")}},
+			[][]*wire.Wire{{aw0, aw1}, {bw0, bw1}}),
+			aw0, aw1, bw0, bw1)
+	}, Meta{
+		Description: "2-bit bus with four writers and two readers",
+		Category:    CPU,
+		Inputs:      []Port{{"d*", "first writer"}, {"ar*", "second writer"}, {"br*", "third writer"}, {"r*", "fourth writer"}},
+		Outputs:     busOutputs,
+	})
+	aluWithBus := Meta{
+		Description: "adder with registers connected by a bus",
+		Category:    CPU,
+		Inputs:      append([]Port{{"d*", "data written to the bus"}}, aluControl...),
+		Outputs:     []Port{{"B(*)", "bus value"}, {"R*", "a, b and result registers"}, {"C(*)", "carry out"}},
+		Sequential:  true,
+	}
+	Register("AluWithBus", func(c *circuit.Circuit) []*wire.Wire {
+		d := c.In("d")
+		ai, bi := c.In("ai"), c.In("bi")
+		ri, ro := c.In("ri"), c.In("ro")
+		cin := c.In("c")
+		c.AddInputValidation(alu.WithBusInputValidation(ai, bi, ri, ro))
+		return alu.WithBus(c.Group(""), d, ai, bi, ri, ro, cin)
+	}, aluWithBus)
+	aluWithBus.Description = "2-bit adder with registers connected by a bus"
+	Register("AluWithBus2", func(c *circuit.Circuit) []*wire.Wire {
+		d0, d1 := c.In("d0"), c.In("d1")
+		ai, bi := c.In("ai"), c.In("bi")
+		ri, ro := c.In("ri"), c.In("ro")
+		cin := c.In("c")
+		c.AddInputValidation(alu.WithBusInputValidation(ai, bi, ri, ro))
+		return alu.WithBus2(c.Group(""), d0, d1, ai, bi, ri, ro, cin)
+	}, aluWithBus)
+	aluWithBus.Description = "N-bit adder with registers connected by a bus with N=2"
+	Register("AluWithBusN", func(c *circuit.Circuit) []*wire.Wire {
+		d0, d1 := c.In("d0"), c.In("d1")
+		ai, bi := c.In("ai"), c.In("bi")
+		ri, ro := c.In("ri"), c.In("ro")
+		cin := c.In("c")
+		c.AddInputValidation(alu.WithBusInputValidation(ai, bi, ri, ro))
+		return alu.WithBusN(c.Group(""), WS(d0, d1), ai, bi, ri, ro, cin)
+	}, aluWithBus)
+	ramMeta := Meta{
+		Description: "RAM with 2 words of 1 bit",
+		Category:    Memory,
+		Inputs:      append([]Port{{"a*", "address"}, {"d*", "data"}}, ramIO...),
+		Outputs:     ramOutputs,
+		Sequential:  true,
+	}
+	Register("RAM", func(c *circuit.Circuit) []*wire.Wire {
+		return slices.Concat(ram.RAM(
+			c.Group(""), WS(c.In("a")), WS(c.In("d")), c.In("i"), c.In("o"))...)
+	}, ramMeta)
+	ramMeta.Description = "RAM with 4 words of 1 bit"
+	Register("RAMa2", func(c *circuit.Circuit) []*wire.Wire {
+		return slices.Concat(ram.RAM(
+			c.Group(""), WS(c.In("a0"), c.In("a1")), WS(c.In("d")), c.In("i"), c.In("o"))...)
+	}, ramMeta)
+	ramMeta.Description = "RAM with 2 words of 2 bits"
+	Register("RAMb2", func(c *circuit.Circuit) []*wire.Wire {
+		return slices.Concat(ram.RAM(
+			c.Group(""), WS(c.In("a")), WS(c.In("d0"), c.In("d1")), c.In("i"), c.In("o"))...)
+	}, ramMeta)
+	ramMeta.Description = "RAM with 4 words of 2 bits"
+	Register("RAMa2b2", func(c *circuit.Circuit) []*wire.Wire {
+		return slices.Concat(ram.RAM(
+			c.Group(""), WS(c.In("a0"), c.In("a1")), WS(c.In("d0"), c.In("d1")),
+			c.In("i"), c.In("o"))...)
+	}, ramMeta)
+	Register("ROM", func(c *circuit.Circuit) []*wire.Wire {
+		var squares []uint64
+		for i := range uint64(8) {
+			squares = append(squares, i*i)
+		}
+		return rom.ROM(c.Group(""), WS(c.In("a0"), c.In("a1"), c.In("a2")), 4, squares)
+	}, Meta{
+		Description: "ROM with the squares of 0 to 7 modulo 16, simulated by a Go function",
+		Category:    Memory,
+		Inputs:      []Port{{"a*", "address"}},
+		Outputs:     []Port{{"q*", "addressed word"}},
+	})
+	aluWithRAM := Meta{
+		Description: "adder and RAM connected by a bus",
+		Category:    CPU,
+		Inputs: append(append([]Port{{"d*", "data written to the bus"}}, aluControl...),
+			Port{"mai", "load the memory address register"}, Port{"mi", "write the bus to the selected word"}, Port{"mo", "drive the selected word to the bus"}),
+		Outputs:    []Port{{"Bd*", "bus value"}, {"C(*)", "carry out"}, {"R*", "a, b, result and memory address registers and words"}},
+		Sequential: true,
+	}
+	Register("AluWithRAM", func(c *circuit.Circuit) []*wire.Wire {
+		d := WS(c.In("d"))
+		ai, bi := c.In("ai"), c.In("bi")
+		ri, ro := c.In("ri"), c.In("ro")
+		cin := c.In("c")
+		mai, mi, mo := c.In("mai"), c.In("mi"), c.In("mo")
+		c.AddInputValidation(alu.WithRAMInputValidation(ai, bi, ri, ro, mai, mi, mo))
+		return alu.WithRAM(c.Group(""), d, ai, bi, ri, ro, cin, mai, mi, mo)
+	}, aluWithRAM)
+	aluWithRAM.Description = "2-bit adder and RAM connected by a bus"
+	Register("AluWithRAM2", func(c *circuit.Circuit) []*wire.Wire {
+		d := WS(c.In("d0"), c.In("d1"))
+		ai, bi := c.In("ai"), c.In("bi")
+		ri, ro := c.In("ri"), c.In("ro")
+		cin := c.In("c")
+		mai, mi, mo := c.In("mai"), c.In("mi"), c.In("mo")
+		c.AddInputValidation(alu.WithRAMInputValidation(ai, bi, ri, ro, mai, mi, mo))
+		return alu.WithRAM(c.Group(""), d, ai, bi, ri, ro, cin, mai, mi, mo)
+	}, aluWithRAM)
+	cpu := Meta{
+		Description: "8-bit CPU with 16 words of RAM running a program that computes 5+28-14 and outputs it",
+		Category:    CPU,
+		Inputs:      []Port{{"e", "clock"}},
+		Outputs: []Port{
+			{"bus[0-9]", "bus value"},
+			{"pc[0-9]", "program counter"},
+			{"step[0-9]", "microcode step"},
+			{"ir[0-9]", "instruction register, with the opcode in the high bits"},
+			{"mar[0-9]", "memory address register"},
+			{"a[0-9]", "A register"},
+			{"b[0-9]", "B register"},
+			{"t[0-9]", "total register, with the sum or difference of A and B"},
+			{"out[0-9]", "output register"},
+		},
+		Sequential: true,
+		Vectors:    Clock(76),
+	}
+	for _, one := range alu.Controls {
+		cpu.Outputs = append(cpu.Outputs, Port{one.Name, one.Doc})
+	}
+	Register("AluWithCPU", func(c *circuit.Circuit) []*wire.Wire {
+		res := alu.WithCPU(c.Group(""), c.In("e"), 4)
+		if err := loadProgram(c, cpuProgram); err != nil {
+			panic(err)
+		}
+		return res
+	}, cpu)
+}
+
+// loadProgram loads the program into the RAM of AluWithCPU.
+func loadProgram(c *circuit.Circuit, words []uint64) error {
+	g, err := c.Find("CPU/RAM")
+	if err != nil {
+		return err
+	}
+	return ram.Load(g, words)
+}
+
+// cpuProgram is the program of AluWithCPU, assembled by package asm from:
+//
+//		LDI 5
+//		ADD x
+//		SUB y
+//		STA res
+//		LDI 0
+//		LDA res
+//		OUT
+//		JMP end
+//		HLT	; skipped by the jump
+//	end:	HLT
+//		.org 13
+//	res:	.word 0
+//	x:	.word 28
+//	y:	.word 14
+var cpuProgram = []uint64{0x55, 0x2e, 0x3f, 0x4d, 0x50, 0x1d, 0xe0, 0x69, 0xf0, 0xf0, 0x00, 0x00, 0x00, 0x00, 0x1c, 0x0e}
@@ -1,17 +1,24 @@
 package lib
 
 import (
+	"flag"
 	"fmt"
-	"os"
+	"path"
 	"slices"
 	"strings"
 	"testing"
 
+	"github.com/kssilveira/circuit-engine/asm"
 	"github.com/kssilveira/circuit-engine/circuit"
 	"github.com/kssilveira/circuit-engine/config"
-	"github.com/kssilveira/circuit-engine/sfmt"
+	"github.com/kssilveira/circuit-engine/golden"
+	"github.com/kssilveira/circuit-engine/lib/gate"
+	"github.com/kssilveira/circuit-engine/lib/ram"
+	"github.com/kssilveira/circuit-engine/wire"
 )
 
+var update = flag.Bool("update", false, "write the golden files instead of comparing with them")
+
 func TestOutputsCombinational(t *testing.T) {
 	inputs := []struct {
 		name        string
@@ -98,6 +105,13 @@ func TestOutputsCombinational(t *testing.T) {
 			sum1 := sum0/2 + inputs["a1"] + inputs["b1"]
 			return []int{sum0 % 2, sum1 % 2, sum1 / 2}
 		},
+	}, {
+		name: "ROM",
+		isValidInt: func(inputs map[string]int) []int {
+			address := inputs["a0"] + 2*inputs["a1"] + 4*inputs["a2"]
+			square := address * address
+			return []int{square & 1, square >> 1 & 1, square >> 2 & 1, square >> 3 & 1}
+		},
 	}, {
 		name: "SRLatch",
 		isValidBool: func() func(inputs map[string]bool) []bool {
@@ -647,24 +661,8 @@ func TestOutputsCombinational(t *testing.T) {
 	for _, in := range inputs {
 		c := circuit.NewCircuit(config.Config{IsUnitTest: true})
 		c.Outs(Example(c, in.name))
-		gotDesc := c.Description()
 		got := c.Simulate()
-		converted := []string{gotDesc, ""}
-		for _, out := range got {
-			var one []string
-			for i, input := range c.Inputs {
-				one = append(one, sfmt.Sprintf("%s(%s)", input.Name, string(out[i])))
-			}
-			one = append(one, "=>")
-			for i, output := range c.Outputs {
-				one = append(one, sfmt.Sprintf("%s(%s)", output.Name, string(out[i+len(c.Inputs)+len("=>")])))
-			}
-			converted = append(converted, strings.Join(one, " "))
-		}
-		converted = append(converted, "")
-		if err := os.WriteFile(fmt.Sprintf("testdata/%s.txt", in.name), []byte(strings.Join(converted, "\n")), 0644); err != nil {
-			t.Errorf("WriteFile got err %v", err)
-		}
+		golden.Check(t, fmt.Sprintf("testdata/%s.txt", in.name), golden.Trace(c, got), *update)
 
 		if in.isValidInt != nil {
 			for _, out := range got {
@@ -740,42 +738,342 @@ func TestOutputsSequential(t *testing.T) {
 		name:   "RAM",
 		inputs: []string{"0000", "0001", "0010", "0001", "1001"},
 	}, {
+		// runs the whole program with the vectors of the example
 		name: "AluWithCPU",
-		inputs: []string{
-			"0", "1", "0",
-			"1", "0", "1", "0", "1", "0", "1", "0",
-			"1", "0", "1", "0", "1", "0", "1", "0",
-			"1", "0", "1", "0", "1", "0", "1", "0",
-			"1", "0", "1", "0", "1", "0", "1", "0",
-			"1", "0", "1", "0", "1", "0", "1", "0",
-		},
 	}}
 	for _, in := range inputs {
 		c := circuit.NewCircuit(config.Config{IsUnitTest: true})
 		c.Outs(Example(c, in.name))
-		gotDesc := c.Description()
+		if in.inputs == nil {
+			meta, _ := Info(in.name)
+			in.inputs = meta.Vectors
+		}
 		for _, inputs := range in.inputs {
 			if len(inputs) != len(c.Inputs) {
 				t.Errorf("SimulateInputs(%q) inputs want %d got %d", in.name, len(inputs), len(c.Inputs))
 			}
 		}
 		got := c.SimulateInputs(in.inputs)
-		converted := []string{gotDesc, ""}
-		for _, out := range got {
-			var one []string
-			for i, input := range c.Inputs {
-				one = append(one, sfmt.Sprintf("%s(%s)", input.Name, string(out[i])))
+		golden.Check(t, fmt.Sprintf("testdata/%s-seq.txt", in.name), golden.Trace(c, got), *update)
+	}
+}
+
+func TestCPUProgram(t *testing.T) {
+	c := circuit.NewCircuit(config.Config{IsUnitTest: true})
+	c.Outs(Example(c, "AluWithCPU"))
+	meta, _ := Info("AluWithCPU")
+	if got := c.SimulateInputs(meta.Vectors); len(got) != len(meta.Vectors) {
+		t.Fatalf("SimulateInputs(AluWithCPU) want %d steps got %d", len(meta.Vectors), len(got))
+	}
+	outputs := map[string]bool{}
+	for _, w := range c.Outputs {
+		outputs[w.Name] = w.Bit.SilentGet()
+	}
+	out := 0
+	for i := range 8 {
+		if outputs[fmt.Sprintf("out%d", i)] {
+			out |= 1 << i
+		}
+	}
+	// the program computes 5+28-14
+	if out != 19 || !outputs["ht"] {
+		t.Errorf("AluWithCPU want out 19 and ht set got out %d and ht %v", out, outputs["ht"])
+	}
+	// the words match the source in the comment of cpuProgram
+	words, err := asm.Assemble(`	LDI 5
+	ADD x
+	SUB y
+	STA res
+	LDI 0
+	LDA res
+	OUT
+	JMP end
+	HLT	; skipped by the jump
+end:	HLT
+	.org 13
+res:	.word 0
+x:	.word 28
+y:	.word 14
+`, 4)
+	if err != nil || !slices.Equal(words, cpuProgram) {
+		t.Errorf("Assemble(cpuProgram source) want %#x got %#x and err %v", cpuProgram, words, err)
+	}
+}
+
+func TestGraphIsStable(t *testing.T) {
+	graph := func() string {
+		c := circuit.NewCircuit(config.Config{MaxPrintDepth: -1, DrawGraph: true, DrawNodes: true, DrawEdges: true, SimulateInputs: []string{"01"}})
+		c.Outs(Example(c, "HalfSum"))
+		return c.Simulate()[0]
+	}
+	want := graph()
+	if got := graph(); want != got {
+		t.Errorf("Graph(HalfSum) changed between runs, want\n%s\ngot\n%s", want, got)
+	}
+	// each Vcc wire is its own node
+	if want, got := 5, strings.Count(want, `[label="Vcc"]`); want != got {
+		t.Errorf("Graph(HalfSum) Vcc nodes want %d got %d", want, got)
+	}
+	inserted := func(insert bool) string {
+		c := circuit.NewCircuit(config.Config{MaxPrintDepth: -1, DrawNodes: true, DrawEdges: true})
+		a, b := c.In("a"), c.In("b")
+		first := c.Group("A")
+		if insert {
+			gate.Not(first, b)
+		}
+		c.Out(gate.Nand(first, a, b))
+		c.Out(gate.Nor(c.Group("B"), a, b))
+		c.Update()
+		return c.Graph()
+	}
+	before, after := inserted(false), inserted(true)
+	if before == after {
+		t.Fatalf("Graph with an inserted gate want changes got\n%s", after)
+	}
+	// the second group keeps its IDs and its wires keep theirs
+	second := func(graph string) string {
+		return graph[strings.Index(graph, "subgraph cluster_g1 {"):]
+	}
+	if want, got := second(before), second(after); want != got {
+		t.Errorf("Graph of group B changed after inserting a gate in group A, want\n%s\ngot\n%s", want, got)
+	}
+	for _, id := range []string{`"w/a"`, `"w/NOR(a,b)"`, `"g1_0"`, `"t1_0_0"`} {
+		if !strings.Contains(after, id) {
+			t.Errorf("Graph want ID %s got\n%s", id, after)
+		}
+	}
+}
+
+func TestCollapsedGroups(t *testing.T) {
+	inputs := []struct {
+		name    string
+		example string
+		cfg     config.Config
+		want    string
+	}{{
+		name:    "String",
+		example: "HalfSum",
+		cfg:     config.Config{MaxPrintDepth: 1, SimulateInputs: []string{"10"}},
+		want:    "|S(a,b) a=1    b=0    =>    S(a,b)=1    C(a,b)=0\n",
+	}, {
+		name:    "Graph",
+		example: "HalfSum",
+		cfg:     config.Config{MaxPrintDepth: 2, DrawGraph: true, DrawEdges: true, SimulateInputs: []string{"10"}},
+		want:    `[shape=record;label="{<i0>a=1|<i1>b=0}|S(a,b)|{<o0>S(a,b)=1|<o1>C(a,b)=0}"];`,
+	}, {
+		// the outputs are the wires read by the circuit outputs, without the dangling carries and decoder lines
+		name:    "String",
+		example: "AluWithCPU",
+		cfg:     config.Config{MaxPrintDepth: 1, SimulateInputs: []string{"0"}},
+		want: "|CPU e=0    =>    " + strings.Join([]string{
+			"step0=0", "step1=0", "step2=0",
+			"ir0=0", "ir1=0", "ir2=0", "ir3=0", "ir4=0", "ir5=0", "ir6=0", "ir7=0",
+			"mar0=0", "mar1=0", "mar2=0", "mar3=0",
+			"pc0=0", "pc1=0", "pc2=0", "pc3=0",
+			"a0=0", "a1=0", "a2=0", "a3=0", "a4=0", "a5=0", "a6=0", "a7=0",
+			"b0=0", "b1=0", "b2=0", "b3=0", "b4=0", "b5=0", "b6=0", "b7=0",
+			"t0=0", "t1=0", "t2=0", "t3=0", "t4=0", "t5=0", "t6=0", "t7=0",
+			"out0=0", "out1=0", "out2=0", "out3=0", "out4=0", "out5=0", "out6=0", "out7=0",
+			"co=1", "mi=1", "ro=0", "ii=0", "ce=0", "ci=0", "io=0", "ai=0", "ao=0",
+			"bi=0", "ti=0", "to=0", "su=0", "ri=0", "oi=0", "ht=0", "sr=0",
+			"bus0=0", "bus1=0", "bus2=0", "bus3=0", "bus4=0", "bus5=0", "bus6=0", "bus7=0",
+		}, "    ") + "\n",
+	}}
+	for _, in := range inputs {
+		c := circuit.NewCircuit(in.cfg)
+		c.Outs(Example(c, in.example))
+		if got := c.Simulate()[0]; !strings.Contains(got, in.want) {
+			t.Errorf("%s(%s) want to contain\n%s\ngot\n%s", in.name, in.example, in.want, got)
+		}
+	}
+}
+
+func TestGateLevel(t *testing.T) {
+	for _, in := range []struct {
+		name    string
+		cfg     config.Config
+		want    []string
+		notWant string
+	}{{
+		name: "String",
+		cfg:  config.Config{MaxPrintDepth: -1, GateLevel: true, SimulateInputs: []string{"10"}},
+		want: []string{"||XOR a=1    b=0    S(a,b)=1\n||AND a=1    b=0    C(a,b)=0\n"},
+	}, {
+		name: "Graph",
+		cfg:  config.Config{MaxPrintDepth: -1, GateLevel: true, DrawGraph: true, DrawNodes: true, DrawEdges: true, SimulateInputs: []string{"10"}},
+		want: []string{
+			`"g0_0_0" [shape=record;label="{<a>a|<b>b}|XOR|{<out>out}"];`,
+			`"w/a" -> "g0_0_0":a [color="red"];`,
+			`"g0_0_0":out -> "w/S(a,b)" [color="red"];`,
+		},
+	}, {
+		// like transistors, gates are nodes
+		name:    "Graph without nodes",
+		cfg:     config.Config{MaxPrintDepth: -1, GateLevel: true, DrawGraph: true, DrawEdges: true, SimulateInputs: []string{"10"}},
+		want:    []string{`subgraph cluster_g0_0 {`},
+		notWant: "shape=record",
+	}} {
+		c := circuit.NewCircuit(in.cfg)
+		c.Outs(Example(c, "HalfSum"))
+		got := c.Simulate()[0]
+		for _, want := range in.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s(HalfSum) want to contain\n%s\ngot\n%s", in.name, want, got)
+			}
+		}
+		if in.notWant != "" && strings.Contains(got, in.notWant) {
+			t.Errorf("%s(HalfSum) want not to contain %q got\n%s", in.name, in.notWant, got)
+		}
+	}
+}
+
+func TestFocus(t *testing.T) {
+	c := circuit.NewCircuit(config.Config{MaxPrintDepth: 1, Focus: "CPU/RAM/Register8#2", SimulateInputs: []string{"0"}})
+	c.Outs(Example(c, "AluWithCPU"))
+	want := "Inputs:   i1=0  bus0=0  o1=0  bus1=0  bus2=0  bus3=0  bus4=0  bus5=0  bus6=0  bus7=0\nOutputs:\n" +
+		"  Rbus010=0\n  Rbus111=0\n  Rbus212=0\n  Rbus313=0\n  Rbus414=0\n  Rbus515=0\n  Rbus616=0\n  Rbus717=0\nComponents: \n"
+	if got := c.Simulate()[0]; !strings.HasPrefix(got, want) {
+		t.Errorf("Simulate(AluWithCPU) want prefix\n%s\ngot\n%s", want, got)
+	}
+	for _, path := range []string{"CPU/Foo", "CPU/RAM/Register8#0", "CPU/RAM/Register8#17"} {
+		if _, err := c.Find(path); err == nil {
+			t.Errorf("Find(%q) want err", path)
+		}
+	}
+	c.Config.Focus = "CPU/Foo"
+	for name, got := range map[string]string{"String": c.String(), "Graph": c.Graph(), "SVG": c.SVG()} {
+		if !strings.HasPrefix(got, "invalid focus: ") {
+			t.Errorf("%s with invalid focus want err got\n%s", name, got)
+		}
+	}
+	if _, err := c.Animate([]string{"0"}); err == nil {
+		t.Errorf("Animate with invalid focus want err")
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	names := ExampleNames()
+	if !slices.IsSorted(names) {
+		t.Errorf("ExampleNames want sorted got %q", names)
+	}
+	for _, name := range names {
+		meta, ok := Info(name)
+		if !ok || meta.Description == "" || meta.Category == "" {
+			t.Errorf("Info(%q) want description and category got %+v", name, meta)
+		}
+		c := circuit.NewCircuit(config.Config{})
+		c.Outs(Example(c, name))
+		for _, ports := range []struct {
+			kind  string
+			wires []*wire.Wire
+			docs  []Port
+		}{{"input", c.Inputs, meta.Inputs}, {"output", c.Outputs, meta.Outputs}} {
+			for _, w := range ports.wires {
+				if !slices.ContainsFunc(ports.docs, func(port Port) bool {
+					ok, _ := path.Match(port.Name, w.Name)
+					return ok
+				}) {
+					t.Errorf("Info(%q) want doc for %s %q", name, ports.kind, w.Name)
+				}
 			}
-			one = append(one, "=>")
-			for i, output := range c.Outputs {
-				one = append(one, sfmt.Sprintf("%s(%s)", output.Name, string(out[i+len(c.Inputs)+len("=>")])))
+		}
+		for _, vector := range meta.Vectors {
+			if len(vector) != len(c.Inputs) {
+				t.Errorf("Info(%q) want vectors with %d inputs got %q", name, len(c.Inputs), vector)
+			}
+		}
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Register(HalfSum) twice want panic")
+		}
+	}()
+	Register("HalfSum", func(_ *circuit.Circuit) []*wire.Wire { return nil }, Meta{})
+}
+
+func TestRandomVectors(t *testing.T) {
+	for _, name := range []string{"AluWithRAM", "AluWithRAM2"} {
+		cfg := config.Config{RandomVectors: 50, RandomSeed: 7}
+		c := circuit.NewCircuit(cfg)
+		c.Outs(Example(c, name))
+		vectors := c.Vectors()
+		if len(vectors) != cfg.RandomVectors {
+			t.Errorf("%s Vectors want %d got %d", name, cfg.RandomVectors, len(vectors))
+		}
+		for _, vector := range vectors {
+			c.SetInputs(vector)
+			if !c.IsValid() {
+				t.Errorf("%s Vectors want valid got %s", name, vector)
 			}
-			converted = append(converted, strings.Join(one, " "))
 		}
-		converted = append(converted, "")
-		if err := os.WriteFile(fmt.Sprintf("testdata/%s-seq.txt", in.name), []byte(strings.Join(converted, "\n")), 0644); err != nil {
-			t.Errorf("WriteFile got err %v", err)
+		again := circuit.NewCircuit(cfg)
+		again.Outs(Example(again, name))
+		if got := again.Vectors(); !slices.Equal(got, vectors) {
+			t.Errorf("%s Vectors with the same seed want %q got %q", name, vectors, got)
+		}
+		cfg.RandomSeed++
+		other := circuit.NewCircuit(cfg)
+		other.Outs(Example(other, name))
+		if got := other.Vectors(); slices.Equal(got, vectors) {
+			t.Errorf("%s Vectors with another seed want different vectors got %q", name, got)
+		}
+	}
+}
+
+func TestBehavioral(t *testing.T) {
+	simulate := func(name string, cfg config.Config) []string {
+		meta, _ := Info(name)
+		cfg.IsUnitTest = true
+		cfg.SimulateInputs = meta.Vectors
+		c := circuit.NewCircuit(cfg)
+		c.Outs(Example(c, name))
+		return c.Simulate()
+	}
+	for _, name := range ExampleNames() {
+		want := simulate(name, config.Config{})
+		cfgs := []config.Config{
+			{Behavioral: []string{"*"}},
+			{Behavioral: []string{"*"}, Transistors: []string{"*/RAM", "*/*/RAM"}},
+		}
+		for _, cfg := range cfgs {
+			if got := simulate(name, cfg); !slices.Equal(got, want) {
+				t.Errorf("%s with behavioral %q transistors %q got\n%s\nwant\n%s", name, cfg.Behavioral, cfg.Transistors, strings.Join(got, "\n"), strings.Join(want, "\n"))
+			}
 		}
+	}
+}
 
+func TestRAMLoad(t *testing.T) {
+	for _, cfg := range []config.Config{{IsUnitTest: true}, {IsUnitTest: true, Behavioral: []string{"*"}}} {
+		c := circuit.NewCircuit(cfg)
+		c.Outs(Example(c, "RAMa2b2"))
+		g, err := c.Find("RAM")
+		if err != nil {
+			t.Fatalf("Find(RAM) got err %v", err)
+		}
+		if err := ram.Load(g, []uint64{1, 2, 3}); err != nil {
+			t.Fatalf("Load got err %v", err)
+		}
+		// read each address with o=1
+		got := c.SimulateInputs([]string{"000001", "100001", "010001", "110001"})
+		want := []string{"000001=>10000000", "100001=>00010000", "010001=>00001100", "110001=>00000000"}
+		if !slices.Equal(got, want) {
+			t.Errorf("Load with behavioral %q want %q got %q", cfg.Behavioral, want, got)
+		}
+		// loading after updates replaces the words
+		if err := ram.Load(g, []uint64{2, 1}); err != nil {
+			t.Fatalf("Load got err %v", err)
+		}
+		got = c.SimulateInputs([]string{"000001", "100001", "110001"})
+		want = []string{"000001=>01000000", "100001=>00100000", "110001=>00000000"}
+		if !slices.Equal(got, want) {
+			t.Errorf("Load after updates with behavioral %q want %q got %q", cfg.Behavioral, want, got)
+		}
+		if err := ram.Load(g, []uint64{4}); err == nil || !strings.Contains(err.Error(), "at most 2 bits") {
+			t.Errorf("Load(4) want err got %v", err)
+		}
+		if err := ram.Load(g, make([]uint64, 5)); err == nil || !strings.Contains(err.Error(), "at most 4") {
+			t.Errorf("Load(5 words) want err got %v", err)
+		}
 	}
 }
@@ -2,6 +2,10 @@
 package ram
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/kssilveira/circuit-engine/behavior"
 	"github.com/kssilveira/circuit-engine/group"
 	"github.com/kssilveira/circuit-engine/lib/decode"
 	"github.com/kssilveira/circuit-engine/lib/gate"
@@ -13,11 +17,60 @@ import (
 // RAM adds a random access memory.
 func RAM(parent *group.Group, a, d []*wire.Wire, ei, eo *wire.Wire) [][]*wire.Wire {
 	group := parent.Group("RAM")
+	if group.Behavioral() {
+		return ramBehavior(group, a, d, ei, eo)
+	}
 	s := decode.Decode(group, a)
 	rei, reo := ramEnable(group, s, ei, eo)
 	return ramRegisters(group, d, rei, reo)
 }
 
+// Load presets the words of a group built by RAM, starting at address 0, with the least significant bit
+// of each word in the first register, and clears the words after them, as if they had been written before the next update.
+func Load(g *group.Group, words []uint64) error {
+	var registers []*group.Group
+	for _, one := range g.Components {
+		if one, ok := one.(*group.Group); ok && strings.HasPrefix(one.Name, "Register") {
+			registers = append(registers, one)
+		}
+	}
+	if len(words) > len(registers) {
+		return fmt.Errorf("image has %d words, want at most %d", len(words), len(registers))
+	}
+	for i := range registers {
+		var word uint64
+		if i < len(words) {
+			word = words[i]
+		}
+		var cells []func(v bool) error
+		for _, one := range registers[i].Components {
+			switch one := one.(type) {
+			case *behavior.Block:
+				// the stored values come first
+				for _, w := range one.Outs[:len(one.Outs)/2] {
+					cells = append(cells, func(v bool) error {
+						w.Bit.SilentSet(v)
+						return nil
+					})
+				}
+			case *group.Group:
+				cells = append(cells, func(v bool) error {
+					return reg.Preset(one, v)
+				})
+			}
+		}
+		if word>>len(cells) != 0 {
+			return fmt.Errorf("word %d is %#x, want at most %d bits", i, word, len(cells))
+		}
+		for j, cell := range cells {
+			if err := cell(word>>j&1 == 1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func ramEnable(group *group.Group, s []*wire.Wire, ei, eo *wire.Wire) ([]*wire.Wire, []*wire.Wire) {
 	var rei, reo []*wire.Wire
 	for i, si := range s {
@@ -50,3 +103,42 @@ func ramRegisters(group *group.Group, d, ei, eo []*wire.Wire) [][]*wire.Wire {
 	}
 	return all
 }
+
+// ramBehavior adds one block per word, in groups named like the ones of reg.N, with the same outputs
+// as the registers preceded by their stored values.
+func ramBehavior(parent *group.Group, a, d []*wire.Wire, ei, eo *wire.Wire) [][]*wire.Wire {
+	ins := append(append(append([]*wire.Wire{}, a...), d...), ei, eo)
+	var all [][]*wire.Wire
+	for i := 0; i < 1<<len(a); i++ {
+		group := parent.Group(sfmt.Sprintf("Register%d", len(d)))
+		var stored, ri []*wire.Wire
+		for j, dj := range d {
+			name := sfmt.Sprintf("%s%d%d", dj.Name, i, j)
+			one := &wire.Wire{Name: "r" + name}
+			// like reg.Register, the cells power up storing 1
+			one.Bit.SilentSet(true)
+			stored = append(stored, one)
+			ri = append(ri, &wire.Wire{Name: "R" + name})
+		}
+		group.Behavior(ins, append(stored, ri...), func(ins, outs []bool) []bool {
+			address := 0
+			for k := range a {
+				if ins[k] {
+					address |= 1 << k
+				}
+			}
+			data, ei, eo := ins[len(a):len(a)+len(d)], ins[len(ins)-2], ins[len(ins)-1]
+			res := make([]bool, len(outs))
+			copy(res, outs[:len(d)])
+			if ei && address == i {
+				copy(res, data)
+			}
+			for k := range d {
+				res[len(d)+k] = res[k] && eo && address == i
+			}
+			return res
+		})
+		all = append(all, ri)
+	}
+	return all
+}
@@ -2,6 +2,10 @@
 package reg
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/kssilveira/circuit-engine/behavior"
 	"github.com/kssilveira/circuit-engine/group"
 	"github.com/kssilveira/circuit-engine/lib/gate"
 	"github.com/kssilveira/circuit-engine/lib/latch"
@@ -12,6 +16,20 @@ import (
 // Register adds a register.
 func Register(parent *group.Group, d, ei, eo *wire.Wire) *wire.Wire {
 	group := parent.Group(sfmt.Sprintf("R%s", d.Name))
+	if group.Behavioral() {
+		// the latch built with transistors powers up storing 1
+		q := &wire.Wire{Name: "r" + group.Name[1:]}
+		q.Bit.SilentSet(true)
+		res := &wire.Wire{Name: group.Name}
+		group.Behavior([]*wire.Wire{d, ei, eo}, []*wire.Wire{q, res}, func(ins, outs []bool) []bool {
+			q := outs[0]
+			if ins[1] {
+				q = ins[0]
+			}
+			return []bool{q, q && ins[2]}
+		})
+		return res
+	}
 	q := &wire.Wire{}
 	latch.DLatchRes(group, q, gate.Or(group, gate.And(group, q, gate.Not(group, ei)), gate.And(group, d, ei)), ei)
 	q.Name = "r" + group.Name[1:]
@@ -20,6 +38,26 @@ func Register(parent *group.Group, d, ei, eo *wire.Wire) *wire.Wire {
 	return res
 }
 
+// Preset sets the value stored by a group built by Register, as if it had been loaded, before the next update.
+func Preset(g *group.Group, v bool) error {
+	for _, one := range g.Components {
+		switch one := one.(type) {
+		case *behavior.Block:
+			one.Outs[0].Bit.SilentSet(v)
+			return nil
+		case *group.Group:
+			if one.Kind != "DLATCH" {
+				continue
+			}
+			// the latch keeps q and its negation
+			one.Outs[0].Bit.SilentSet(v)
+			one.Outs[1].Bit.SilentSet(!v)
+			return nil
+		}
+	}
+	return fmt.Errorf("group %q is not a register", g.Name)
+}
+
 // Register2 adds a 2-bit register.
 func Register2(parent *group.Group, d1, d2, ei, eo *wire.Wire) []*wire.Wire {
 	group := parent.Group("Register2")
@@ -37,3 +75,64 @@ func N(parent *group.Group, d []*wire.Wire, ei, eo *wire.Wire) []*wire.Wire {
 	}
 	return res
 }
+
+// FlipFlopRes adds a register triggered by the rising edge of e, which loads d when ei is set, using the result parameter.
+//
+// Unlike Register, it powers up storing 0, and has no output enable.
+func FlipFlopRes(parent *group.Group, q, d, ei, e *wire.Wire) *wire.Wire {
+	group := parent.Group(sfmt.Sprintf("F%s", q.Name))
+	if group.Behavioral() {
+		// the master follows the next value while e is unset, and the slave copies it while e is set
+		m := &wire.Wire{Name: "m" + q.Name}
+		group.Behavior([]*wire.Wire{d, ei, e}, []*wire.Wire{m, q}, func(ins, outs []bool) []bool {
+			m, q := outs[0], outs[1]
+			switch {
+			case ins[2]:
+				q = m
+			case ins[1]:
+				m = ins[0]
+			default:
+				m = q
+			}
+			return []bool{m, q}
+		})
+		return q
+	}
+	latch.DFlipFlopRes(group, q, gate.Or(group, gate.And(group, q, gate.Not(group, ei)), gate.And(group, d, ei)), e)
+	reset(group)
+	return q
+}
+
+// reset clears the latches inside the group, which power up storing 1.
+func reset(g *group.Group) {
+	for _, one := range g.Components {
+		one, ok := one.(*group.Group)
+		if !ok {
+			continue
+		}
+		if one.Kind != "DLATCH" {
+			reset(one)
+			continue
+		}
+		one.Outs[0].Bit.SilentSet(false)
+		one.Outs[1].Bit.SilentSet(true)
+	}
+}
+
+// FlipFlopN adds an N-bit register triggered by the rising edge of e, with outputs named after the group, see FlipFlopRes.
+func FlipFlopN(parent *group.Group, name string, d []*wire.Wire, ei, e *wire.Wire) []*wire.Wire {
+	var q []*wire.Wire
+	for i := range d {
+		q = append(q, &wire.Wire{Name: sfmt.Sprintf("%s%d", strings.ToLower(name), i)})
+	}
+	return FlipFlopNRes(parent, name, q, d, ei, e)
+}
+
+// FlipFlopNRes adds an N-bit register triggered by the rising edge of e using the result parameter, see FlipFlopRes.
+func FlipFlopNRes(parent *group.Group, name string, q, d []*wire.Wire, ei, e *wire.Wire) []*wire.Wire {
+	group := parent.Group(name)
+	for i, di := range d {
+		FlipFlopRes(group, q[i], di, ei, e)
+	}
+	return q
+}
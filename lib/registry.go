@@ -0,0 +1,96 @@
+package lib
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/kssilveira/circuit-engine/circuit"
+	"github.com/kssilveira/circuit-engine/wire"
+)
+
+// Builder builds an example in the circuit and returns its outputs.
+type Builder func(c *circuit.Circuit) []*wire.Wire
+
+// Category groups related examples.
+type Category string
+
+// Categories of the examples in this package.
+const (
+	Gate   Category = "gate"
+	Arith  Category = "arith"
+	Memory Category = "memory"
+	CPU    Category = "cpu"
+)
+
+// Port documents an input or output.
+type Port struct {
+	// Name is the wire name, or a path.Match pattern such as "Rr*" for repeated outputs.
+	Name string `json:"name"`
+	Doc  string `json:"doc"`
+}
+
+// Meta describes an example.
+type Meta struct {
+	Description string   `json:"description"`
+	Category    Category `json:"category"`
+	Inputs      []Port   `json:"inputs,omitempty"`
+	Outputs     []Port   `json:"outputs,omitempty"`
+	// Sequential is set for circuits with state, whose outputs depend on previous inputs.
+	Sequential bool `json:"sequential,omitempty"`
+	// Vectors contains the default input vectors, one character per input, instead of the ones from circuit.Vectors.
+	Vectors []string `json:"vectors,omitempty"`
+}
+
+type example struct {
+	builder Builder
+	meta    Meta
+}
+
+var examples = map[string]example{}
+
+// Register adds an example, so other packages can add circuits from their init functions.
+//
+// It panics if the name is empty or already registered, or if the builder is nil.
+func Register(name string, builder Builder, meta Meta) {
+	if name == "" || builder == nil {
+		panic(fmt.Sprintf("Register(%q) needs a name and a builder", name))
+	}
+	if _, ok := examples[name]; ok {
+		panic(fmt.Sprintf("Register(%q) called twice", name))
+	}
+	examples[name] = example{builder: builder, meta: meta}
+}
+
+// Example builds the example with the given name and returns its outputs, or nil if it does not exist.
+func Example(c *circuit.Circuit, name string) []*wire.Wire {
+	res, ok := examples[name]
+	if !ok {
+		return nil
+	}
+	return res.builder(c)
+}
+
+// Info returns the metadata of the example with the given name.
+func Info(name string) (Meta, bool) {
+	res, ok := examples[name]
+	return res.meta, ok
+}
+
+// ExampleNames returns the sorted example names.
+func ExampleNames() []string {
+	var res []string
+	for name := range examples {
+		res = append(res, name)
+	}
+	slices.Sort(res)
+	return res
+}
+
+// Clock returns n vectors that alternate a single clock input, starting at 0.
+func Clock(n int) []string {
+	var res []string
+	for i := range n {
+		res = append(res, wire.BoolToString(i%2 == 1))
+	}
+	return res
+}
@@ -0,0 +1,38 @@
+// Package rom defines read-only memory components.
+package rom
+
+import (
+	"github.com/kssilveira/circuit-engine/group"
+	"github.com/kssilveira/circuit-engine/sfmt"
+	"github.com/kssilveira/circuit-engine/wire"
+)
+
+// ROM adds a read-only memory with the given words, simulated as a behavioral block.
+//
+// The address a has the least significant bit first, and the outputs contain the bits of the
+// addressed word, least significant first, or 0 past the last word.
+func ROM(parent *group.Group, a []*wire.Wire, width int, words []uint64) []*wire.Wire {
+	group := parent.Group(sfmt.Sprintf("ROM%dx%d", 1<<len(a), width))
+	var res []*wire.Wire
+	for i := range width {
+		res = append(res, &wire.Wire{Name: sfmt.Sprintf("q%d", i)})
+	}
+	group.Behavior(a, res, func(ins, _ []bool) []bool {
+		address := 0
+		for i, in := range ins {
+			if in {
+				address |= 1 << i
+			}
+		}
+		var word uint64
+		if address < len(words) {
+			word = words[address]
+		}
+		outs := make([]bool, width)
+		for i := range outs {
+			outs[i] = word>>i&1 == 1
+		}
+		return outs
+	})
+	return res
+}
@@ -21,6 +21,20 @@ func HalfSum(parent *group.Group, a, b *wire.Wire) []*wire.Wire {
 // Sum adds an adder.
 func Sum(parent *group.Group, a, b, cin *wire.Wire) []*wire.Wire {
 	group := parent.Group(sfmt.Sprintf("S(%s,%s,%s)", a.Name, b.Name, cin.Name))
+	if group.Behavioral() {
+		res := &wire.Wire{Name: group.Name}
+		carry := &wire.Wire{Name: sfmt.Sprintf("C(%s,%s)", a.Name, b.Name)}
+		group.Behavior([]*wire.Wire{a, b, cin}, []*wire.Wire{res, carry}, func(ins, _ []bool) []bool {
+			count := 0
+			for _, in := range ins {
+				if in {
+					count++
+				}
+			}
+			return []bool{count%2 == 1, count >= 2}
+		})
+		return []*wire.Wire{res, carry}
+	}
 	s1 := HalfSum(group, a, b)
 	s2 := HalfSum(group, s1[0], cin)
 	s2[0].Name = group.Name
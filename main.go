@@ -1,70 +1,392 @@
-// Package main simulates a given example circuit.
+// Package main builds, simulates, draws and exports the example circuits, one subcommand at a time.
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"strings"
 
 	"github.com/kssilveira/circuit-engine/circuit"
+	"github.com/kssilveira/circuit-engine/component"
 	"github.com/kssilveira/circuit-engine/config"
+	"github.com/kssilveira/circuit-engine/format/blif"
+	"github.com/kssilveira/circuit-engine/format/jsonfmt"
+	"github.com/kssilveira/circuit-engine/format/logisim"
+	"github.com/kssilveira/circuit-engine/format/memimage"
+	"github.com/kssilveira/circuit-engine/format/verilog"
+	"github.com/kssilveira/circuit-engine/group"
 	"github.com/kssilveira/circuit-engine/lib"
+	"github.com/kssilveira/circuit-engine/lib/ram"
+	"github.com/kssilveira/circuit-engine/netlist"
+)
+
+// Exit codes, so scripts can tell invalid usage from failed commands.
+const (
+	exitFailure = 1
+	exitUsage   = 2
 )
 
 func main() {
-	if err := all(); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
+	err := run(os.Args[1:], os.Stdout)
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return
 	}
+	fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	var usage usageError
+	if errors.As(err, &usage) {
+		os.Exit(exitUsage)
+	}
+	os.Exit(exitFailure)
 }
 
-func all() error {
-	exampleName := flag.String("example_name", "TransistorEmitter", "example name")
-	c := circuit.NewCircuit(flagsToConfig())
-	outs := lib.Example(c, *exampleName)
-	if len(outs) == 0 {
-		return fmt.Errorf("invalid --example_name %q, valid names are %q", *exampleName, lib.ExampleNames())
+// usageError is an invalid command line, as opposed to a command that failed.
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string {
+	return e.err.Error()
+}
+
+func usageErrorf(format string, a ...any) error {
+	return usageError{fmt.Errorf(format, a...)}
+}
+
+// command is a subcommand with its own flags.
+type command struct {
+	name string
+	help string
+	run  func(args []string, out io.Writer) error
+}
+
+func commands() []command {
+	return []command{
+		{"list", "list the examples", list},
+		{"simulate", "simulate input vectors and print each step", simulate},
+		{"truthtable", "print the inputs and outputs of each step as a table", truthtable},
+		{"draw", "draw the circuit as a graphviz graph, an SVG image or an animated HTML page", drawCommand},
+		{"stats", "count the nets and components of the circuit", stats},
+		{"test", "compare each step with the expected outputs", test},
+		{"export", "write the circuit as JSON, Verilog, a Verilog testbench, BLIF or SPICE", export},
+		{"asm", "assemble the program file given as the first argument, or the standard input, into a RAM image for the CPU of AluWithCPU, or disassemble an image", asmCommand},
+		{"repl", "read commands such as load, set, step, run, watch, print and save from the standard input", replCommand},
+		{"tui", "toggle the inputs from the terminal and show the values live", tuiCommand},
+		{"serve", "serve the examples over HTTP", serve},
 	}
-	c.Outs(outs)
-	res := c.Simulate()
-	fmt.Println(strings.Join(res, "\n\n"))
-	return draw(res, c.Config)
-}
-
-func flagsToConfig() config.Config {
-	maxPrintDepth := flag.Int("max_print_depth", -1, "max print depth")
-	drawGraph := flag.Bool("draw_graph", false, "draw graph")
-	drawSingleGraph := flag.Bool("draw_single_graph", false, "draw single graph")
-	drawNodes := flag.Bool("draw_nodes", true, "draw nodes")
-	drawEdges := flag.Bool("draw_edges", true, "draw edges")
-	drawShapePoint := flag.Bool("draw_shape_point", false, "draw shape point")
-	isUnitTest := flag.Bool("is_unit_test", false, "is unit test")
-	simulateInputs := flag.String("simulate_inputs", "", "simulate inputs")
-	flag.Parse()
-	return config.Config{
-		MaxPrintDepth:   *maxPrintDepth,
-		DrawGraph:       *drawGraph,
-		DrawSingleGraph: *drawSingleGraph,
-		DrawNodes:       *drawNodes,
-		DrawEdges:       *drawEdges,
-		DrawShapePoint:  *drawShapePoint,
-		IsUnitTest:      *isUnitTest,
-		SimulateInputs:  strings.Split(*simulateInputs, ","),
-	}
-}
-
-func draw(res []string, cfg config.Config) error {
-	if !cfg.DrawGraph {
+}
+
+func run(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		usage(os.Stderr)
+		return usageErrorf("missing command")
+	}
+	name := args[0]
+	if name == "help" || name == "-h" || name == "--help" {
+		usage(out)
+		return nil
+	}
+	var names []string
+	for _, one := range commands() {
+		if one.name == name {
+			return one.run(args[1:], out)
+		}
+		names = append(names, one.name)
+	}
+	return usageErrorf("unknown command %q, valid commands are %q", name, names)
+}
+
+func usage(out io.Writer) {
+	fmt.Fprintln(out, "usage: circuit-engine <command> [example] [flags]")
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "commands:")
+	for _, one := range commands() {
+		fmt.Fprintf(out, "  %-12s%s\n", one.name, one.help)
+	}
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "run circuit-engine <command> --help for the flags of each command")
+}
+
+// flags contains the flags of a command, including where the circuit comes from.
+type flags struct {
+	*flag.FlagSet
+	src    source
+	cfg    config.Config
+	inputs string
+	// behavioral and transistors contain comma-separated patterns, see config.Config.Behavioral
+	behavioral  string
+	transistors string
+	ramImage    string
+	ram         string
+	// arg receives the positional argument, named by argName in the usage, and is nil for commands without one.
+	arg     *string
+	argName string
+}
+
+func newFlags(name string, withSource bool) *flags {
+	res := &flags{FlagSet: flag.NewFlagSet(name, flag.ContinueOnError), cfg: config.Config{MaxPrintDepth: -1, DrawNodes: true, DrawEdges: true}}
+	help := ""
+	for _, one := range commands() {
+		if one.name == name {
+			help = one.help
+		}
+	}
+	res.Usage = func() {
+		out := res.Output()
+		if res.arg != nil {
+			fmt.Fprintf(out, "usage: circuit-engine %s [%s] [flags]\n\n", name, res.argName)
+		} else {
+			fmt.Fprintf(out, "usage: circuit-engine %s [flags]\n\n", name)
+		}
+		fmt.Fprintf(out, "%s\n\nflags:\n", help)
+		res.PrintDefaults()
+	}
+	if !withSource {
+		return res
+	}
+	res.arg, res.argName = &res.src.exampleName, "example"
+	res.StringVar(&res.src.exampleName, "example", "", "example name, which may also be given as the first argument, see the list command")
+	res.StringVar(&res.src.readJSON, "read_json", "", "read the circuit from this JSON file instead of building an example")
+	res.StringVar(&res.src.readVerilog, "read_verilog", "", "read the circuit from this gate-level Verilog file instead of building an example")
+	res.StringVar(&res.src.verilogTop, "verilog_top", "", "top module for --read_verilog, defaults to the only module not instantiated")
+	res.StringVar(&res.src.readBLIF, "read_blif", "", "read the circuit from this BLIF file instead of building an example")
+	res.StringVar(&res.src.blifModel, "blif_model", "", "top model for --read_blif, defaults to the first model")
+	res.StringVar(&res.src.readLogisim, "read_logisim", "", "read the circuit from this Logisim .circ file instead of building an example")
+	res.StringVar(&res.src.logisimCircuit, "logisim_circuit", "", "top circuit for --read_logisim, defaults to the main circuit")
+	res.StringVar(&res.behavioral, "behavioral", "", "comma-separated group path patterns, such as * or CPU/RAM, whose lib/gate, sum.Sum, reg.Register and ram.RAM blocks are simulated by Go functions instead of transistors")
+	res.StringVar(&res.transistors, "transistors", "", "comma-separated group path patterns inside --behavioral groups that are still built with transistors")
+	res.StringVar(&res.ramImage, "ram_image", "", "preset the RAM with this Intel-HEX (.hex), raw binary (.bin) or text image, one word per byte or number")
+	res.StringVar(&res.ram, "ram", "", "group path of the RAM loaded by --ram_image, defaults to the first group named RAM")
+	return res
+}
+
+// printing adds the flags that select what is printed and drawn.
+func (f *flags) printing() {
+	f.IntVar(&f.cfg.MaxPrintDepth, "max_print_depth", -1, "collapse groups at this depth into boxes, -1 prints all groups")
+	f.StringVar(&f.cfg.Focus, "focus", "", "print and draw only the group at this path, such as CPU/RAM")
+	f.gateLevel()
+}
+
+func (f *flags) gateLevel() {
+	f.BoolVar(&f.cfg.GateLevel, "gate_level", false, "stop at lib/gate groups, print and draw them as gates, and use gate primitives")
+}
+
+func (f *flags) simulation(help string) {
+	f.StringVar(&f.inputs, "inputs", "", help)
+}
+
+// random adds the flags that select the default vectors without --inputs.
+func (f *flags) random() {
+	f.IntVar(&f.cfg.RandomVectors, "random_vectors", 10, "number of random vectors, at least 1")
+	f.Uint64Var(&f.cfg.RandomSeed, "seed", 42, "seed of the random vectors, at least 1")
+	f.IntVar(&f.cfg.ExhaustiveInputs, "exhaustive_inputs", 7, "simulate all vectors up to this many inputs instead of random ones, -1 for none")
+	f.DurationVar(&f.cfg.TimeBudget, "time_budget", 0, "stop simulating after this duration, such as 2s, 0 for no limit")
+}
+
+// parse parses the flags, which may come before and after the positional argument, and rejects 0 for the nonZero flags.
+func (f *flags) parse(args []string) error {
+	if err := f.parseArgs(args); err != nil {
+		return err
+	}
+	var err error
+	f.Visit(func(one *flag.Flag) {
+		if want, ok := nonZero[one.Name]; ok && one.Value.String() == "0" && err == nil {
+			err = usageErrorf("invalid --%s 0, want %s", one.Name, want)
+		}
+	})
+	return err
+}
+
+// nonZero contains the valid values of the flags that reject 0, which config.Config replaces with the default.
+var nonZero = map[string]string{
+	"random_vectors":    "at least 1",
+	"seed":              "at least 1",
+	"exhaustive_inputs": "at least 1, or -1 for none",
+}
+
+// parseArgs parses the flags before and after the positional argument.
+func (f *flags) parseArgs(args []string) error {
+	if err := f.Parse(args); err != nil {
+		return f.wrap(err)
+	}
+	if f.NArg() == 0 {
+		return nil
+	}
+	if f.arg == nil || *f.arg != "" {
+		return usageErrorf("unexpected arguments %q", f.Args())
+	}
+	*f.arg = f.Arg(0)
+	if err := f.Parse(f.Args()[1:]); err != nil {
+		return f.wrap(err)
+	}
+	if f.NArg() > 0 {
+		return usageErrorf("unexpected arguments %q", f.Args())
+	}
+	return nil
+}
+
+func (f *flags) wrap(err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	return usageError{err}
+}
+
+// vectors returns the --inputs vectors, the default vectors of the example, or nil to use the defaults of circuit.Vectors.
+func (f *flags) vectors() []string {
+	if f.inputs != "" {
+		return strings.Split(f.inputs, ",")
+	}
+	if meta, ok := lib.Info(f.src.exampleName); ok && !f.src.reads() {
+		return meta.Vectors
+	}
+	return nil
+}
+
+// build builds the circuit with the --inputs vectors.
+func (f *flags) build() (*circuit.Circuit, error) {
+	if f.src == (source{}) {
+		return nil, usageErrorf("missing example name or --read_* flag, see the list command")
+	}
+	cfg := f.cfg
+	cfg.SimulateInputs = f.vectors()
+	for _, one := range []struct {
+		name     string
+		flag     string
+		patterns *[]string
+	}{{"behavioral", f.behavioral, &cfg.Behavioral}, {"transistors", f.transistors, &cfg.Transistors}} {
+		if one.flag == "" {
+			continue
+		}
+		*one.patterns = strings.Split(one.flag, ",")
+		for _, pattern := range *one.patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, usageErrorf("invalid --%s pattern %q: %v", one.name, pattern, err)
+			}
+		}
+	}
+	c, err := f.src.build(cfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, vector := range cfg.SimulateInputs {
+		if len(vector) != len(c.Inputs) || strings.Trim(vector, "01") != "" {
+			return nil, usageErrorf("invalid --inputs vector %q, want %d digits 0 or 1", vector, len(c.Inputs))
+		}
+	}
+	if cfg.Focus != "" {
+		if _, err := c.Find(cfg.Focus); err != nil {
+			return nil, usageErrorf("invalid --focus: %v", err)
+		}
+	}
+	if err := f.loadRAM(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// loadRAM presets the RAM at --ram, or the first group named RAM, with the words of --ram_image.
+func (f *flags) loadRAM(c *circuit.Circuit) error {
+	if f.ramImage == "" {
 		return nil
 	}
-	for i, graph := range res {
-		if i >= 4 || (cfg.DrawSingleGraph && i >= 1) {
-			break
+	var g *group.Group
+	if f.ram != "" {
+		var err error
+		if g, err = c.Find(f.ram); err != nil {
+			return usageErrorf("invalid --ram: %v", err)
 		}
-		if err := os.WriteFile(fmt.Sprintf("%d.dot", i), []byte(graph), 0644); err != nil {
-			return fmt.Errorf("WriteFile got err %v", err)
+	} else {
+		netlist.Walk(c.Components, func(one component.Component) {
+			if one, ok := one.(*group.Group); ok && g == nil && one.Name == "RAM" {
+				g = one
+			}
+		})
+		if g == nil {
+			return usageErrorf("--ram_image needs a group named RAM or --ram")
 		}
 	}
+	data, err := os.ReadFile(f.ramImage)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", f.ramImage, err)
+	}
+	words, err := memimage.Read(f.ramImage, data)
+	if err != nil {
+		return fmt.Errorf("invalid --ram_image: %v", err)
+	}
+	if err := ram.Load(g, words); err != nil {
+		return fmt.Errorf("invalid --ram_image: %v", err)
+	}
+	return nil
+}
+
+// source selects where the circuit comes from.
+type source struct {
+	exampleName string
+	readJSON    string
+	readVerilog string
+	verilogTop  string
+	readBLIF    string
+	blifModel   string
+	readLogisim string
+	// logisimCircuit is the top circuit of readLogisim
+	logisimCircuit string
+}
+
+// reads returns whether the circuit is read from a file instead of built from an example.
+func (s source) reads() bool {
+	return s.readJSON != "" || s.readVerilog != "" || s.readBLIF != "" || s.readLogisim != ""
+}
+
+func (s source) build(cfg config.Config) (*circuit.Circuit, error) {
+	if s.readJSON != "" {
+		data, err := os.ReadFile(s.readJSON)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", s.readJSON, err)
+		}
+		return jsonfmt.Unmarshal(data, cfg)
+	}
+	c := circuit.NewCircuit(cfg)
+	if s.readVerilog != "" {
+		data, err := os.ReadFile(s.readVerilog)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", s.readVerilog, err)
+		}
+		return c, verilog.Import(c, string(data), s.verilogTop)
+	}
+	if s.readBLIF != "" {
+		data, err := os.ReadFile(s.readBLIF)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", s.readBLIF, err)
+		}
+		return c, blif.Import(c, string(data), s.blifModel)
+	}
+	if s.readLogisim != "" {
+		data, err := os.ReadFile(s.readLogisim)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", s.readLogisim, err)
+		}
+		return c, logisim.Import(c, string(data), s.logisimCircuit)
+	}
+	outs := lib.Example(c, s.exampleName)
+	if len(outs) == 0 {
+		return nil, usageErrorf("invalid example %q, valid names are %q", s.exampleName, lib.ExampleNames())
+	}
+	c.Outs(outs)
+	return c, nil
+}
+
+// write writes the result to the file, or to the output without a file.
+func write(out io.Writer, file, res string) error {
+	if file == "" {
+		_, err := io.WriteString(out, res)
+		return err
+	}
+	if err := os.WriteFile(file, []byte(res), 0644); err != nil {
+		return fmt.Errorf("write %s: %w", file, err)
+	}
 	return nil
 }
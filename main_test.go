@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	want := filepath.Join(t.TempDir(), "want.txt")
+	if err := os.WriteFile(want, []byte("# HalfSum\n00=>00\n11=>10\n"), 0644); err != nil {
+		t.Fatalf("WriteFile got err %v", err)
+	}
+	image := filepath.Join(t.TempDir(), "image.txt")
+	if err := os.WriteFile(image, []byte("1 0\n"), 0644); err != nil {
+		t.Fatalf("WriteFile got err %v", err)
+	}
+	program := filepath.Join(t.TempDir(), "program.s")
+	if err := os.WriteFile(program, []byte("LDI 7\nOUT\nHLT\n"), 0644); err != nil {
+		t.Fatalf("WriteFile got err %v", err)
+	}
+	invalid := filepath.Join(t.TempDir(), "invalid.s")
+	if err := os.WriteFile(invalid, []byte("FOO\n"), 0644); err != nil {
+		t.Fatalf("WriteFile got err %v", err)
+	}
+	inputs := []struct {
+		args      []string
+		want      string
+		wantErr   string
+		wantUsage bool
+	}{{
+		args: []string{"list"},
+		want: "Alu\nAlu2\n",
+	}, {
+		args: []string{"simulate", "HalfSum", "--format", "unit", "--inputs", "01,11"},
+		want: "01=>10\n11=>01\n",
+	}, {
+		args: []string{"simulate", "--format", "unit", "--example", "Not"},
+		want: "0=>1\n1=>0\n",
+	}, {
+		args: []string{"truthtable", "HalfSum", "--inputs", "11"},
+		want: "a b | S(a,b) C(a,b)\n1 1 | 0      1\n",
+	}, {
+		args: []string{"stats", "Not"},
+		want: "inputs 1\noutputs 1\n",
+	}, {
+		args:      []string{"simulate", "Nand", "--inputs", "0101"},
+		wantErr:   `invalid --inputs vector "0101", want 2 digits 0 or 1`,
+		wantUsage: true,
+	}, {
+		args:      []string{"simulate", "Nand", "--inputs", "00,2x"},
+		wantErr:   `invalid --inputs vector "2x"`,
+		wantUsage: true,
+	}, {
+		args:      []string{"simulate", "Alu2", "--random_vectors", "0"},
+		wantErr:   "invalid --random_vectors 0, want at least 1",
+		wantUsage: true,
+	}, {
+		args:      []string{"truthtable", "--seed", "0", "Alu2"},
+		wantErr:   "invalid --seed 0, want at least 1",
+		wantUsage: true,
+	}, {
+		args:    []string{"simulate", "--read_json", "missing.json"},
+		wantErr: "read missing.json: open missing.json: no such file or directory",
+	}, {
+		args:      []string{"asm", program, program},
+		wantErr:   "unexpected arguments",
+		wantUsage: true,
+	}, {
+		args:      []string{"serve", "HalfSum"},
+		wantErr:   `unexpected arguments ["HalfSum"]`,
+		wantUsage: true,
+	}, {
+		args: []string{"stats", "AluWithCPU", "--focus", "CPU/RAM"},
+		want: "inputs 14\noutputs 128\n",
+	}, {
+		args:    []string{"test", "HalfSum", "--want", want},
+		want:    want + ":3: want 11=>10 got 11=>01\n",
+		wantErr: "1 of 2 steps failed",
+	}, {
+		args: []string{"simulate", "RAM", "--format", "unit", "--inputs", "0001,1001", "--ram_image", image},
+		want: "0001=>10\n1001=>00\n",
+	}, {
+		args:      []string{"simulate", "HalfSum", "--ram_image", image},
+		wantErr:   "--ram_image needs a group named RAM",
+		wantUsage: true,
+	}, {
+		args: []string{"asm", program},
+		want: "0x57\n0xe0\n0xf0\n",
+	}, {
+		args: []string{"asm", image, "--disassemble"},
+		want: "\t.word 0x1\t; 0: 0x01\n\tNOP\t; 1: 0x00\n",
+	}, {
+		args: []string{"simulate", "AluWithCPU", "--format", "trace", "--inputs", "0,1"},
+		want: "pc  step  instruction  a  b  t  mar  bus  out  control lines\n0   T0    NOP          0  0  0  0    0    0    co mi\n",
+	}, {
+		args:    []string{"asm", invalid},
+		wantErr: `line 1: invalid mnemonic "FOO"`,
+	}, {
+		args:      []string{"simulate", "Foo"},
+		wantErr:   `invalid example "Foo"`,
+		wantUsage: true,
+	}, {
+		args:      []string{"simulate", "HalfSum", "--format", "foo"},
+		wantErr:   `invalid --format "foo"`,
+		wantUsage: true,
+	}, {
+		args:      []string{"foo"},
+		wantErr:   `unknown command "foo"`,
+		wantUsage: true,
+	}}
+	for _, in := range inputs {
+		var out strings.Builder
+		err := run(in.args, &out)
+		if !strings.HasPrefix(out.String(), in.want) {
+			t.Errorf("run(%q) want prefix\n%s\ngot\n%s", in.args, in.want, out.String())
+		}
+		if in.wantErr == "" {
+			if err != nil {
+				t.Errorf("run(%q) got err %v", in.args, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), in.wantErr) {
+			t.Errorf("run(%q) want err %q got %v", in.args, in.wantErr, err)
+		}
+		var usage usageError
+		if got := errors.As(err, &usage); got != in.wantUsage {
+			t.Errorf("run(%q) want usage error %v got %v", in.args, in.wantUsage, got)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Package netlist indexes the nets and components of a circuit.
+package netlist
+
+import (
+	"github.com/kssilveira/circuit-engine/behavior"
+	"github.com/kssilveira/circuit-engine/circuit"
+	"github.com/kssilveira/circuit-engine/component"
+	"github.com/kssilveira/circuit-engine/group"
+	"github.com/kssilveira/circuit-engine/jointwire"
+	"github.com/kssilveira/circuit-engine/transistor"
+	"github.com/kssilveira/circuit-engine/wire"
+)
+
+// Netlist contains the nets of a circuit in first-use order.
+type Netlist struct {
+	Nets  []*wire.Wire
+	index map[*wire.Wire]int
+}
+
+// New indexes the nets of the given circuit.
+func New(c *circuit.Circuit) *Netlist {
+	res := &Netlist{index: map[*wire.Wire]int{}}
+	for _, input := range c.Inputs {
+		res.Add(input)
+	}
+	for _, output := range c.Outputs {
+		res.Add(output)
+	}
+	Walk(c.Components, func(component component.Component) {
+		for _, wire := range Wires(component) {
+			res.Add(wire)
+		}
+	})
+	return res
+}
+
+// Add adds a net if needed and returns its index.
+func (n *Netlist) Add(w *wire.Wire) int {
+	if index, ok := n.index[w]; ok {
+		return index
+	}
+	n.index[w] = len(n.Nets)
+	n.Nets = append(n.Nets, w)
+	return n.index[w]
+}
+
+// Index returns the index of the given net and whether it is known.
+func (n *Netlist) Index(w *wire.Wire) (int, bool) {
+	index, ok := n.index[w]
+	return index, ok
+}
+
+// Walk calls fn for every component, visiting groups before their components.
+func Walk(components []component.Component, fn func(component.Component)) {
+	for _, component := range components {
+		fn(component)
+		if g, ok := component.(*group.Group); ok {
+			Walk(g.Components, fn)
+		}
+	}
+}
+
+// Wires returns the wires directly connected to a component, which are the ports for groups.
+func Wires(c component.Component) []*wire.Wire {
+	switch c := c.(type) {
+	case *group.Group:
+		return append(append([]*wire.Wire{}, c.Ins...), c.Outs...)
+	case *transistor.Transistor:
+		return []*wire.Wire{c.Base, c.Collector, c.Emitter, c.CollectorOut}
+	case *jointwire.JointWire:
+		return []*wire.Wire{c.Res, c.A, c.B}
+	case *behavior.Block:
+		return append(append([]*wire.Wire{}, c.Ins...), c.Outs...)
+	}
+	return nil
+}
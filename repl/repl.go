@@ -0,0 +1,297 @@
+// Package repl runs simulation commands read line by line, such as "load AluWithCPU" and "run 40".
+package repl
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kssilveira/circuit-engine/circuit"
+	"github.com/kssilveira/circuit-engine/config"
+	"github.com/kssilveira/circuit-engine/lib"
+	"github.com/kssilveira/circuit-engine/session"
+	"github.com/kssilveira/circuit-engine/wire"
+)
+
+// Help describes the commands.
+const Help = `load <example>           build an example, such as AluWithCPU
+set <input> <0|1> ...    set inputs without updating
+step [n]                 update n times, 1 by default
+run <n>                  toggle the clock input and update, n times
+clock <input>            set the clock input used by run, e by default
+watch <pattern>          watch the nets matching the pattern, such as d*
+print [path]             print the values, or the group at the path, such as CPU/A
+save <file>              save the state and the simulated inputs as JSON
+help                     show this help
+quit                     exit`
+
+// REPL contains the loaded example and the inputs of each update.
+type REPL struct {
+	Config config.Config
+	// Prompt is written before reading each line.
+	Prompt  string
+	example string
+	clock   string
+	session *session.Session
+	// vectors contains the inputs of each update, in the simulate --inputs format
+	vectors []string
+}
+
+// State is the saved state of a REPL.
+type State struct {
+	Example string `json:"example"`
+	Steps   int    `json:"steps"`
+	// Vectors replays the session with simulate --inputs.
+	Vectors string          `json:"vectors"`
+	Inputs  []session.Value `json:"inputs"`
+	Outputs []session.Value `json:"outputs"`
+	Probes  []session.Value `json:"probes"`
+}
+
+// New creates a REPL without an example.
+func New(cfg config.Config) *REPL {
+	return &REPL{Config: cfg, clock: "e"}
+}
+
+// Run runs each line until "quit" or the end of the input.
+//
+// Command errors are written to the output and do not stop the REPL.
+func (r *REPL) Run(in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	for {
+		if _, err := io.WriteString(out, r.Prompt); err != nil {
+			return err
+		}
+		if !scanner.Scan() {
+			return scanner.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "quit" || line == "exit" {
+			return nil
+		}
+		res, err := r.Exec(line)
+		if err != nil {
+			res = fmt.Sprintf("error: %v", err)
+		}
+		if res == "" {
+			continue
+		}
+		if _, err := io.WriteString(out, res+"\n"); err != nil {
+			return err
+		}
+	}
+}
+
+// Exec runs a single command and returns its output.
+//
+// Empty lines and lines starting with "#" are ignored.
+func (r *REPL) Exec(line string) (string, error) {
+	args := strings.Fields(line)
+	if len(args) == 0 || strings.HasPrefix(args[0], "#") {
+		return "", nil
+	}
+	command, args := args[0], args[1:]
+	if command == "help" {
+		return Help, nil
+	}
+	if command == "load" {
+		if len(args) != 1 {
+			return "", fmt.Errorf("usage: load <example>")
+		}
+		return r.load(args[0])
+	}
+	if r.session == nil {
+		return "", fmt.Errorf("no example loaded, use load <example>")
+	}
+	switch command {
+	case "set":
+		return "", r.set(args)
+	case "step":
+		count, err := count(args, 1)
+		if err != nil {
+			return "", fmt.Errorf("usage: step [n]: %v", err)
+		}
+		return r.step(count, false)
+	case "run":
+		if len(args) != 1 {
+			return "", fmt.Errorf("usage: run <n>")
+		}
+		count, err := count(args, 0)
+		if err != nil {
+			return "", fmt.Errorf("usage: run <n>: %v", err)
+		}
+		return r.step(count, true)
+	case "clock":
+		if len(args) != 1 {
+			return "", fmt.Errorf("usage: clock <input>")
+		}
+		if _, err := r.session.Input(args[0]); err != nil {
+			return "", err
+		}
+		r.clock = args[0]
+		return "", nil
+	case "watch":
+		if len(args) != 1 {
+			return "", fmt.Errorf("usage: watch <pattern>")
+		}
+		added, err := r.session.Watch(args[0])
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("watching %d more nets", added), nil
+	case "print":
+		if len(args) > 1 {
+			return "", fmt.Errorf("usage: print [path]")
+		}
+		if len(args) == 0 {
+			return r.values(), nil
+		}
+		return r.print(args[0])
+	case "save":
+		if len(args) != 1 {
+			return "", fmt.Errorf("usage: save <file>")
+		}
+		return "", r.save(args[0])
+	}
+	return "", fmt.Errorf("unknown command %q, see help", command)
+}
+
+func (r *REPL) load(example string) (string, error) {
+	c := circuit.NewCircuit(r.Config)
+	outs := lib.Example(c, example)
+	if len(outs) == 0 {
+		return "", fmt.Errorf("invalid example %q, valid names are %q", example, lib.ExampleNames())
+	}
+	c.Outs(outs)
+	r.example, r.clock = example, "e"
+	r.session = session.New(c)
+	r.vectors = []string{r.session.Inputs()}
+	return fmt.Sprintf("loaded %s with inputs %q", example, r.session.InputNames()), nil
+}
+
+func (r *REPL) set(args []string) error {
+	if len(args) == 0 || len(args)%2 != 0 {
+		return fmt.Errorf("usage: set <input> <0|1> ...")
+	}
+	for i := 0; i < len(args); i += 2 {
+		if args[i+1] != "0" && args[i+1] != "1" {
+			return fmt.Errorf("invalid value %q for input %q, want 0 or 1", args[i+1], args[i])
+		}
+		if err := r.session.Set(args[i], args[i+1] == "1"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// step updates the circuit count times, toggling the clock first if tick is set, and returns one line per update.
+func (r *REPL) step(count int, tick bool) (string, error) {
+	clock := -1
+	if tick {
+		index, err := r.session.Input(r.clock)
+		if err != nil {
+			return "", fmt.Errorf("invalid clock: %v, use clock <input>", err)
+		}
+		clock = index
+	}
+	var res []string
+	for range count {
+		var err error
+		if clock >= 0 {
+			_, err = r.session.Toggle(clock)
+		} else {
+			_, err = r.session.Update()
+		}
+		if err != nil {
+			return strings.Join(res, "\n"), err
+		}
+		r.vectors = append(r.vectors, r.session.Inputs())
+		res = append(res, r.line())
+	}
+	return strings.Join(res, "\n"), nil
+}
+
+// line returns the inputs and outputs in the unit test format, followed by the probes.
+func (r *REPL) line() string {
+	res := []string{fmt.Sprintf("step %d: %s=>%s", r.session.Steps, r.session.Inputs(), bits(r.session.Circuit.Outputs))}
+	for _, probe := range r.session.Probes {
+		res = append(res, fmt.Sprintf("%v", *probe))
+	}
+	return strings.Join(res, " ")
+}
+
+func (r *REPL) values() string {
+	res := []string{fmt.Sprintf("%s step %d", r.example, r.session.Steps)}
+	for _, one := range []struct {
+		title string
+		wires []*wire.Wire
+	}{
+		{"Inputs:", r.session.Circuit.Inputs},
+		{"Outputs:", r.session.Circuit.Outputs},
+		{"Probes:", r.session.Probes},
+	} {
+		if len(one.wires) == 0 {
+			continue
+		}
+		res = append(res, one.title)
+		for _, w := range one.wires {
+			res = append(res, fmt.Sprintf("  %v", *w))
+		}
+	}
+	return strings.Join(res, "\n")
+}
+
+// print returns the group at the path with its ports, as printed by --focus.
+func (r *REPL) print(path string) (string, error) {
+	if _, err := r.session.Circuit.Find(path); err != nil {
+		return "", err
+	}
+	view := *r.session.Circuit
+	view.Config.Focus = path
+	return view.String(), nil
+}
+
+func (r *REPL) save(file string) error {
+	res := State{
+		Example: r.example,
+		Steps:   r.session.Steps,
+		Vectors: strings.Join(r.vectors, ","),
+		Inputs:  session.Values(r.session.Circuit.Inputs),
+		Outputs: session.Values(r.session.Circuit.Outputs),
+		Probes:  session.Values(r.session.Probes),
+	}
+	data, err := json.MarshalIndent(res, "", " ")
+	if err != nil {
+		return fmt.Errorf("json.MarshalIndent got err %v", err)
+	}
+	if err := os.WriteFile(file, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("WriteFile got err %v", err)
+	}
+	return nil
+}
+
+func count(args []string, fallback int) (int, error) {
+	if len(args) == 0 {
+		return fallback, nil
+	}
+	if len(args) > 1 {
+		return 0, fmt.Errorf("too many arguments")
+	}
+	res, err := strconv.Atoi(args[0])
+	if err != nil || res < 0 {
+		return 0, fmt.Errorf("invalid count %q", args[0])
+	}
+	return res, nil
+}
+
+func bits(wires []*wire.Wire) string {
+	var res string
+	for _, w := range wires {
+		res += wire.BoolToString(w.Bit.SilentGet())
+	}
+	return res
+}
@@ -0,0 +1,106 @@
+package repl
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kssilveira/circuit-engine/circuit"
+	"github.com/kssilveira/circuit-engine/config"
+	"github.com/kssilveira/circuit-engine/lib"
+)
+
+func TestRun(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "state.json")
+	script := strings.Join([]string{
+		"step",
+		"load HalfSum",
+		"# comment",
+		"set a 1",
+		"step",
+		"set b 1 a 0",
+		"watch NAND*",
+		"step",
+		"set c 1",
+		"print",
+		"save " + file,
+		"quit",
+		"step",
+	}, "\n")
+	var out strings.Builder
+	if err := New(config.Config{}).Run(strings.NewReader(script), &out); err != nil {
+		t.Fatalf("Run got err %v", err)
+	}
+	want := strings.Join([]string{
+		"error: no example loaded, use load <example>",
+		`loaded HalfSum with inputs ["a" "b"]`,
+		"step 1: 10=>10",
+		"watching 2 more nets",
+		"step 2: 01=>10 NAND(a,b)=1 NAND(a,b)-wire=0",
+		`error: input "c" not found, valid names are ["a" "b"]`,
+		"HalfSum step 2",
+		"Inputs:",
+		"  a=0",
+		"  b=1",
+		"Outputs:",
+		"  S(a,b)=1",
+		"  C(a,b)=0",
+		"Probes:",
+		"  NAND(a,b)=1",
+		"  NAND(a,b)-wire=0",
+		"",
+	}, "\n")
+	if got := out.String(); got != want {
+		t.Errorf("Run want\n%s\ngot\n%s", want, got)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("ReadFile got err %v", err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatalf("Unmarshal got err %v", err)
+	}
+	if state.Vectors != "00,10,01" || state.Steps != 2 {
+		t.Errorf("save want vectors 00,10,01 and 2 steps got %+v", state)
+	}
+}
+
+func TestRunReplaysSimulateInputs(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "state.json")
+	r := New(config.Config{})
+	var last string
+	for _, line := range []string{"load AluWithCPU", "watch bus*", "run 42", "print CPU/A", "save " + file} {
+		res, err := r.Exec(line)
+		if err != nil {
+			t.Fatalf("Exec(%q) got err %v", line, err)
+		}
+		if line == "run 42" {
+			lines := strings.Split(res, "\n")
+			last = lines[len(lines)-1]
+		}
+		if line == "print CPU/A" && !strings.HasPrefix(res, "Inputs: ") {
+			t.Errorf("Exec(%q) want the group got\n%s", line, res)
+		}
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("ReadFile got err %v", err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatalf("Unmarshal got err %v", err)
+	}
+	want := "0" + strings.Repeat(",1,0", 21)
+	if state.Vectors != want {
+		t.Fatalf("save want vectors %s got %s", want, state.Vectors)
+	}
+	c := circuit.NewCircuit(config.Config{IsUnitTest: true})
+	c.Outs(lib.Example(c, "AluWithCPU"))
+	got := c.SimulateInputs(strings.Split(state.Vectors, ","))
+	if !strings.HasPrefix(last, "step 42: "+got[len(got)-1]+" ") {
+		t.Errorf("run want the replayed outputs %s got %s", got[len(got)-1], last)
+	}
+}
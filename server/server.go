@@ -0,0 +1,299 @@
+// Package server hosts circuit sessions over HTTP, with a JSON API and a bundled static page.
+package server
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kssilveira/circuit-engine/circuit"
+	"github.com/kssilveira/circuit-engine/config"
+	"github.com/kssilveira/circuit-engine/lib"
+	"github.com/kssilveira/circuit-engine/session"
+)
+
+//go:embed static
+var static embed.FS
+
+// MaxSteps is the largest StepRequest.Count.
+const MaxSteps = 1000
+
+// IdleTimeout is the default Server.IdleTimeout.
+const IdleTimeout = 30 * time.Minute
+
+// Server contains the sessions, each identified by a number.
+type Server struct {
+	// IdleTimeout removes the sessions that are not used for this duration, 0 keeps them.
+	IdleTimeout time.Duration
+	// mu guards the sessions map, and each entry has its own lock.
+	mu       sync.Mutex
+	sessions map[string]*entry
+	next     int
+	now      func() time.Time
+}
+
+type entry struct {
+	mu      sync.Mutex
+	example string
+	session *session.Session
+	// changes contains the nets that changed in the last step
+	changes []session.Change
+	// used is when the session was last found, guarded by Server.mu
+	used time.Time
+}
+
+// BuildRequest builds an example into a new session.
+type BuildRequest struct {
+	Example string `json:"example"`
+	// MaxPrintDepth collapses deeper groups in the drawing, -1 draws all groups
+	MaxPrintDepth *int     `json:"max_print_depth"`
+	GateLevel     bool     `json:"gate_level"`
+	Probes        []string `json:"probes"`
+}
+
+// StepRequest sets inputs and then updates the circuit Count times, at most MaxSteps.
+type StepRequest struct {
+	Inputs map[string]bool `json:"inputs"`
+	Count  int             `json:"count"`
+}
+
+// State is the state of a session.
+type State struct {
+	ID      string          `json:"id"`
+	Example string          `json:"example"`
+	Steps   int             `json:"steps"`
+	Inputs  []session.Value `json:"inputs"`
+	Outputs []session.Value `json:"outputs"`
+	Probes  []session.Value `json:"probes"`
+	Changes []session.Value `json:"changes"`
+}
+
+// New creates a server without sessions, which expire after IdleTimeout.
+func New() *Server {
+	return &Server{IdleTimeout: IdleTimeout, sessions: map[string]*entry{}, now: time.Now}
+}
+
+// Handler returns the handler of the static page and the API:
+//
+//	GET  /api/examples                   the sorted example names
+//	POST /api/sessions                   builds a BuildRequest and returns its State
+//	GET  /api/sessions/{id}              returns the State
+//	POST /api/sessions/{id}/inputs       sets the inputs in a name to value object without updating
+//	POST /api/sessions/{id}/step         applies a StepRequest and returns the State
+//	POST /api/sessions/{id}/probes       adds the probes matching the "pattern" field
+//	GET  /api/sessions/{id}/svg          draws the circuit
+//
+// Requests to different sessions run in parallel, and idle sessions expire after IdleTimeout.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(fmt.Sprintf("fs.Sub got err %v", err))
+	}
+	mux.Handle("GET /", http.FileServerFS(files))
+	mux.HandleFunc("GET /api/examples", s.examples)
+	mux.HandleFunc("POST /api/sessions", s.build)
+	mux.HandleFunc("GET /api/sessions/{id}", s.handle(s.state))
+	mux.HandleFunc("POST /api/sessions/{id}/inputs", s.handle(s.inputs))
+	mux.HandleFunc("POST /api/sessions/{id}/step", s.handle(s.step))
+	mux.HandleFunc("POST /api/sessions/{id}/probes", s.handle(s.probes))
+	mux.HandleFunc("GET /api/sessions/{id}/svg", s.svg)
+	return mux
+}
+
+func (s *Server) examples(w http.ResponseWriter, _ *http.Request) {
+	reply(w, lib.ExampleNames(), nil)
+}
+
+func (s *Server) build(w http.ResponseWriter, r *http.Request) {
+	var req BuildRequest
+	if err := decode(r, &req); err != nil {
+		reply(w, nil, fmt.Errorf("invalid request: %v", err))
+		return
+	}
+	cfg := config.Config{MaxPrintDepth: -1, DrawNodes: true, DrawEdges: true, GateLevel: req.GateLevel}
+	if req.MaxPrintDepth != nil {
+		cfg.MaxPrintDepth = *req.MaxPrintDepth
+	}
+	c := circuit.NewCircuit(cfg)
+	outs := lib.Example(c, req.Example)
+	if len(outs) == 0 {
+		reply(w, nil, fmt.Errorf("invalid example %q", req.Example))
+		return
+	}
+	c.Outs(outs)
+	one := &entry{example: req.Example, session: session.New(c)}
+	for _, pattern := range req.Probes {
+		if _, err := one.session.Watch(pattern); err != nil {
+			reply(w, nil, err)
+			return
+		}
+	}
+	s.mu.Lock()
+	s.expire()
+	id := strconv.Itoa(s.next)
+	s.next++
+	one.used = s.now()
+	s.sessions[id] = one
+	s.mu.Unlock()
+	reply(w, one.state(id), nil)
+}
+
+// expire removes the sessions that are idle for longer than IdleTimeout, with s.mu held.
+func (s *Server) expire() {
+	if s.IdleTimeout <= 0 {
+		return
+	}
+	for id, one := range s.sessions {
+		if s.now().Sub(one.used) > s.IdleTimeout {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+// find returns the session with the id in the path, locked, or replies with status 404.
+func (s *Server) find(w http.ResponseWriter, r *http.Request) (string, *entry, bool) {
+	s.mu.Lock()
+	s.expire()
+	id := r.PathValue("id")
+	one, ok := s.sessions[id]
+	if ok {
+		one.used = s.now()
+	}
+	s.mu.Unlock()
+	if !ok {
+		http.Error(w, fmt.Sprintf("session %q not found", id), http.StatusNotFound)
+		return "", nil, false
+	}
+	one.mu.Lock()
+	return id, one, true
+}
+
+// handle runs fn on the session and replies with its state.
+func (s *Server) handle(fn func(*entry, *http.Request) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, one, ok := s.find(w, r)
+		if !ok {
+			return
+		}
+		defer one.mu.Unlock()
+		if err := fn(one, r); err != nil {
+			reply(w, nil, err)
+			return
+		}
+		reply(w, one.state(id), nil)
+	}
+}
+
+func (s *Server) state(*entry, *http.Request) error {
+	return nil
+}
+
+func (s *Server) inputs(one *entry, r *http.Request) error {
+	var inputs map[string]bool
+	if err := decode(r, &inputs); err != nil {
+		return fmt.Errorf("invalid request: %v", err)
+	}
+	return one.set(inputs)
+}
+
+func (s *Server) step(one *entry, r *http.Request) error {
+	req := StepRequest{Count: 1}
+	if err := decode(r, &req); err != nil {
+		return fmt.Errorf("invalid request: %v", err)
+	}
+	if req.Count < 0 || req.Count > MaxSteps {
+		return fmt.Errorf("invalid count %d, want 0 to %d", req.Count, MaxSteps)
+	}
+	if err := one.set(req.Inputs); err != nil {
+		return err
+	}
+	one.changes = nil
+	for range req.Count {
+		changes, err := one.session.Update()
+		if err != nil {
+			return err
+		}
+		one.changes = append(one.changes, changes...)
+	}
+	return nil
+}
+
+func (s *Server) probes(one *entry, r *http.Request) error {
+	var req struct {
+		Pattern string `json:"pattern"`
+	}
+	if err := decode(r, &req); err != nil {
+		return fmt.Errorf("invalid request: %v", err)
+	}
+	_, err := one.session.Watch(req.Pattern)
+	return err
+}
+
+func (s *Server) svg(w http.ResponseWriter, r *http.Request) {
+	_, one, ok := s.find(w, r)
+	if !ok {
+		return
+	}
+	defer one.mu.Unlock()
+	w.Header().Set("Content-Type", "image/svg+xml")
+	fmt.Fprint(w, one.session.Circuit.SVG())
+}
+
+func (e *entry) set(inputs map[string]bool) error {
+	// sort the names so errors do not depend on the map order
+	var names []string
+	for name := range inputs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if err := e.session.Set(name, inputs[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *entry) state(id string) State {
+	res := State{
+		ID:      id,
+		Example: e.example,
+		Steps:   e.session.Steps,
+		Inputs:  session.Values(e.session.Circuit.Inputs),
+		Outputs: session.Values(e.session.Circuit.Outputs),
+		Probes:  session.Values(e.session.Probes),
+		Changes: []session.Value{},
+	}
+	for _, change := range e.changes {
+		res.Changes = append(res.Changes, session.Value{Name: change.Wire.Name, Value: change.Value})
+	}
+	return res
+}
+
+// decode decodes the JSON request body, which may be empty.
+func decode(r *http.Request, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// reply writes the value as JSON, or the error as {"error": "..."} with status 400.
+func reply(w http.ResponseWriter, v any, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		v = map[string]string{"error": err.Error()}
+	}
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
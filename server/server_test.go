@@ -0,0 +1,103 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServer(t *testing.T) {
+	s := httptest.NewServer(New().Handler())
+	defer s.Close()
+	inputs := []struct {
+		method string
+		path   string
+		body   string
+		status int
+		want   string
+	}{{
+		method: "GET", path: "/api/examples", status: http.StatusOK,
+		want: `"HalfSum",`,
+	}, {
+		method: "POST", path: "/api/sessions", body: `{"example": "HalfSum", "probes": ["*-wire1"]}`, status: http.StatusOK,
+		want: `{"id":"0","example":"HalfSum","steps":0,"inputs":[{"name":"a","value":false},{"name":"b","value":false}],"outputs":[{"name":"S(a,b)","value":false},{"name":"C(a,b)","value":false}],"probes":[{"name":"OR(a,b)-wire1","value":false}],"changes":[]}`,
+	}, {
+		method: "POST", path: "/api/sessions/0/step", body: `{"inputs": {"a": true}}`, status: http.StatusOK,
+		want: `"steps":1,"inputs":[{"name":"a","value":true},{"name":"b","value":false}],"outputs":[{"name":"S(a,b)","value":true},{"name":"C(a,b)","value":false}],"probes":[{"name":"OR(a,b)-wire1","value":true}],"changes":[{"name":"a","value":true}`,
+	}, {
+		method: "POST", path: "/api/sessions/0/step", body: `{"count": 1001}`, status: http.StatusBadRequest,
+		want: `{"error":"invalid count 1001, want 0 to 1000"}`,
+	}, {
+		method: "POST", path: "/api/sessions/0/inputs", body: `{"c": true}`, status: http.StatusBadRequest,
+		want: `{"error":"input \"c\" not found, valid names are [\"a\" \"b\"]"}`,
+	}, {
+		method: "GET", path: "/api/sessions/1", status: http.StatusNotFound,
+		want: `session "1" not found`,
+	}, {
+		method: "GET", path: "/api/sessions/0/svg", status: http.StatusOK,
+		want: `<svg xmlns="http://www.w3.org/2000/svg"`,
+	}, {
+		method: "GET", path: "/", status: http.StatusOK,
+		want: `<title>circuit-engine</title>`,
+	}}
+	for _, in := range inputs {
+		req, err := http.NewRequest(in.method, s.URL+in.path, strings.NewReader(in.body))
+		if err != nil {
+			t.Fatalf("NewRequest got err %v", err)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s got err %v", in.method, in.path, err)
+		}
+		body, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			t.Fatalf("ReadAll got err %v", err)
+		}
+		if res.StatusCode != in.status || !strings.Contains(string(body), in.want) {
+			t.Errorf("%s %s want %d containing\n%s\ngot %d\n%s", in.method, in.path, in.status, in.want, res.StatusCode, body)
+		}
+	}
+}
+
+func TestExpire(t *testing.T) {
+	server := New()
+	now := time.Now()
+	server.now = func() time.Time { return now }
+	s := httptest.NewServer(server.Handler())
+	defer s.Close()
+	status := func(method, path, body string) int {
+		req, err := http.NewRequest(method, s.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("NewRequest got err %v", err)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s got err %v", method, path, err)
+		}
+		res.Body.Close()
+		return res.StatusCode
+	}
+	for _, in := range []struct {
+		idle   time.Duration
+		method string
+		path   string
+		status int
+	}{
+		{0, "POST", "/api/sessions", http.StatusOK},
+		{0, "POST", "/api/sessions", http.StatusOK},
+		{IdleTimeout * 2 / 3, "GET", "/api/sessions/0", http.StatusOK},
+		// session 1 was not used since it was built
+		{IdleTimeout * 2 / 3, "GET", "/api/sessions/1", http.StatusNotFound},
+		{0, "GET", "/api/sessions/0", http.StatusOK},
+		{IdleTimeout + time.Second, "GET", "/api/sessions/0/svg", http.StatusNotFound},
+	} {
+		now = now.Add(in.idle)
+		if got := status(in.method, in.path, `{"example": "Not"}`); got != in.status {
+			t.Errorf("%s %s after %v want %d got %d", in.method, in.path, in.idle, in.status, got)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Package session explores a circuit one update at a time, for interactive modes.
+package session
+
+import (
+	"fmt"
+	"path"
+	"slices"
+
+	"github.com/kssilveira/circuit-engine/circuit"
+	"github.com/kssilveira/circuit-engine/netlist"
+	"github.com/kssilveira/circuit-engine/wire"
+)
+
+// Session contains a circuit, its probes and the net values after the last update.
+type Session struct {
+	Circuit *circuit.Circuit
+	// Probes contains the watched nets, in the order they were added.
+	Probes []*wire.Wire
+	// Steps contains the number of updates.
+	Steps  int
+	nets   *netlist.Netlist
+	values []bool
+}
+
+// Change is a net that changed value in the last update.
+type Change struct {
+	Wire  *wire.Wire
+	Value bool
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s=%s", c.Wire.Name, wire.BoolToString(c.Value))
+}
+
+// Value is the value of a net, as encoded in JSON by the interactive modes.
+type Value struct {
+	Name  string `json:"name"`
+	Value bool   `json:"value"`
+}
+
+// Values returns the current values of the wires, and an empty list without wires.
+func Values(wires []*wire.Wire) []Value {
+	res := []Value{}
+	for _, w := range wires {
+		res = append(res, Value{w.Name, w.Bit.SilentGet()})
+	}
+	return res
+}
+
+// New creates a session and updates the circuit with the current inputs.
+func New(c *circuit.Circuit) *Session {
+	res := &Session{Circuit: c, nets: netlist.New(c)}
+	c.Update()
+	res.values = res.snapshot()
+	return res
+}
+
+func (s *Session) snapshot() []bool {
+	var res []bool
+	for _, net := range s.nets.Nets {
+		res = append(res, net.Bit.SilentGet())
+	}
+	return res
+}
+
+// Input returns the index of the input with the given name.
+func (s *Session) Input(name string) (int, error) {
+	res := -1
+	for i, input := range s.Circuit.Inputs {
+		if input.Name != name {
+			continue
+		}
+		if res >= 0 {
+			return 0, fmt.Errorf("input %q is ambiguous", name)
+		}
+		res = i
+	}
+	if res < 0 {
+		return 0, fmt.Errorf("input %q not found, valid names are %q", name, s.InputNames())
+	}
+	return res, nil
+}
+
+// InputNames returns the input names.
+func (s *Session) InputNames() []string {
+	var res []string
+	for _, input := range s.Circuit.Inputs {
+		res = append(res, input.Name)
+	}
+	return res
+}
+
+// Set sets the input with the given name without updating the circuit.
+func (s *Session) Set(name string, v bool) error {
+	index, err := s.Input(name)
+	if err != nil {
+		return err
+	}
+	s.Circuit.Inputs[index].Bit.SilentSet(v)
+	return nil
+}
+
+// Toggle toggles the input with the given index and updates the circuit, see Update.
+// When the new inputs fail validation, it toggles the input back.
+func (s *Session) Toggle(index int) ([]Change, error) {
+	input := s.Circuit.Inputs[index]
+	input.Bit.SilentSet(!input.Bit.SilentGet())
+	res, err := s.Update()
+	if err != nil {
+		input.Bit.SilentSet(!input.Bit.SilentGet())
+	}
+	return res, err
+}
+
+// Update updates the circuit and returns the nets that changed, unless the inputs fail validation.
+func (s *Session) Update() ([]Change, error) {
+	if !s.Circuit.IsValid() {
+		return nil, fmt.Errorf("inputs %s fail validation", s.Inputs())
+	}
+	s.Circuit.Update()
+	s.Steps++
+	values := s.snapshot()
+	var res []Change
+	for i, net := range s.nets.Nets {
+		if values[i] != s.values[i] && net.Name != "Unused" {
+			res = append(res, Change{Wire: net, Value: values[i]})
+		}
+	}
+	s.values = values
+	return res, nil
+}
+
+// Inputs returns the input values, one character per input.
+func (s *Session) Inputs() string {
+	var res string
+	for _, input := range s.Circuit.Inputs {
+		res += wire.BoolToString(input.Bit.SilentGet())
+	}
+	return res
+}
+
+// Watch adds the nets whose names match the pattern to the probes, and returns how many were added.
+//
+// The pattern uses path.Match syntax, such as "d*".
+func (s *Session) Watch(pattern string) (int, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return 0, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+	}
+	res := 0
+	for _, net := range s.nets.Nets {
+		if ok, _ := path.Match(pattern, net.Name); !ok {
+			continue
+		}
+		if !slices.Contains(s.Probes, net) {
+			s.Probes = append(s.Probes, net)
+			res++
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,128 @@
+// Package trace prints the execution of the CPU added by alu.WithCPU, one line per microcode step.
+package trace
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kssilveira/circuit-engine/asm"
+	"github.com/kssilveira/circuit-engine/circuit"
+	"github.com/kssilveira/circuit-engine/lib/alu"
+	"github.com/kssilveira/circuit-engine/wire"
+)
+
+// CPU contains the nets of a CPU added by alu.WithCPU, least significant bit first.
+type CPU struct {
+	Bus, PC, Step, IR, MAR, A, B, T, Out []*wire.Wire
+	// Controls contains the control lines in the order of alu.Controls.
+	Controls []*wire.Wire
+}
+
+// Find returns the nets of the CPU, which are outputs of the circuit named like the ones returned by alu.WithCPU.
+func Find(c *circuit.Circuit) (*CPU, error) {
+	outputs := map[string]*wire.Wire{}
+	for _, w := range c.Outputs {
+		if _, ok := outputs[w.Name]; !ok {
+			outputs[w.Name] = w
+		}
+	}
+	var err error
+	bits := func(name string) []*wire.Wire {
+		var res []*wire.Wire
+		for i := 0; ; i++ {
+			w, ok := outputs[fmt.Sprintf("%s%d", name, i)]
+			if !ok {
+				break
+			}
+			res = append(res, w)
+		}
+		if len(res) == 0 && err == nil {
+			err = fmt.Errorf("missing output %s0, want the outputs of alu.WithCPU", name)
+		}
+		return res
+	}
+	res := &CPU{
+		Bus:  bits("bus"),
+		PC:   bits("pc"),
+		Step: bits("step"),
+		IR:   bits("ir"),
+		MAR:  bits("mar"),
+		A:    bits("a"),
+		B:    bits("b"),
+		T:    bits("t"),
+		Out:  bits("out"),
+	}
+	for _, one := range alu.Controls {
+		w, ok := outputs[one.Name]
+		if !ok && err == nil {
+			err = fmt.Errorf("missing output %s, want the outputs of alu.WithCPU", one.Name)
+		}
+		res.Controls = append(res.Controls, w)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// value returns the number stored by the wires, least significant bit first.
+func value(wires []*wire.Wire) uint64 {
+	var res uint64
+	for i, w := range wires {
+		if w.Bit.SilentGet() {
+			res |= 1 << i
+		}
+	}
+	return res
+}
+
+// Header contains the column names of Line.
+const Header = "pc\tstep\tinstruction\ta\tb\tt\tmar\tbus\tout\tcontrol lines"
+
+// Line returns the current program counter, step, disassembled instruction register, registers, bus
+// and set control lines, separated by tabs.
+func (cpu *CPU) Line() string {
+	var controls []string
+	for i, w := range cpu.Controls {
+		if w.Bit.SilentGet() {
+			controls = append(controls, alu.Controls[i].Name)
+		}
+	}
+	return fmt.Sprintf("%d\tT%d\t%s\t%d\t%d\t%d\t%d\t%d\t%d\t%s",
+		value(cpu.PC), value(cpu.Step), asm.Disassemble(value(cpu.IR), len(cpu.PC)),
+		value(cpu.A), value(cpu.B), value(cpu.T), value(cpu.MAR), value(cpu.Bus), value(cpu.Out), strings.Join(controls, " "))
+}
+
+// Run simulates the vectors and returns one line per rising edge of the clock input, after the registers load,
+// with aligned columns.
+func Run(c *circuit.Circuit, vectors []string, clock string) (string, error) {
+	cpu, err := Find(c)
+	if err != nil {
+		return "", err
+	}
+	e := -1
+	for i, input := range c.Inputs {
+		if input.Name == clock {
+			e = i
+		}
+	}
+	if e < 0 {
+		return "", fmt.Errorf("missing clock input %q", clock)
+	}
+	var res strings.Builder
+	w := tabwriter.NewWriter(&res, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, Header)
+	last := false
+	c.Run(vectors, func(int) {
+		now := c.Inputs[e].Bit.SilentGet()
+		if now && !last {
+			fmt.Fprintln(w, cpu.Line())
+		}
+		last = now
+	})
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+	return res.String(), nil
+}
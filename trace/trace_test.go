@@ -0,0 +1,49 @@
+package trace
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kssilveira/circuit-engine/circuit"
+	"github.com/kssilveira/circuit-engine/config"
+	"github.com/kssilveira/circuit-engine/lib"
+)
+
+func TestRun(t *testing.T) {
+	c := circuit.NewCircuit(config.Config{})
+	c.Outs(lib.Example(c, "AluWithCPU"))
+	meta, _ := lib.Info("AluWithCPU")
+	got, err := Run(c, meta.Vectors, "e")
+	if err != nil {
+		t.Fatalf("Run got err %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if want := len(meta.Vectors) / 2; len(lines) != want+1 {
+		t.Errorf("Run want header and %d lines got %d", want, len(lines))
+	}
+	for _, want := range [][]string{
+		strings.Fields(Header),
+		{"3", "T4", "SUB", "15", "33", "14", "19", "15", "0", "0", "ti", "su"},
+		{"10", "T2", "HLT", "19", "14", "19", "9", "0", "19", "ht"},
+	} {
+		if !slices.ContainsFunc(lines, func(line string) bool {
+			return slices.Equal(strings.Fields(line), want)
+		}) {
+			t.Errorf("Run want line %q got\n%s", want, got)
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	c := circuit.NewCircuit(config.Config{})
+	c.Outs(lib.Example(c, "HalfSum"))
+	if _, err := Run(c, nil, "e"); err == nil || !strings.Contains(err.Error(), "missing output bus0") {
+		t.Errorf("Run(HalfSum) want err got %v", err)
+	}
+	c = circuit.NewCircuit(config.Config{})
+	c.Outs(lib.Example(c, "AluWithCPU"))
+	if _, err := Run(c, nil, "clk"); err == nil || !strings.Contains(err.Error(), `missing clock input "clk"`) {
+		t.Errorf("Run(clk) want err got %v", err)
+	}
+}
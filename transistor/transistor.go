@@ -6,6 +6,7 @@ import (
 
 	"github.com/kssilveira/circuit-engine/config"
 	"github.com/kssilveira/circuit-engine/draw"
+	"github.com/kssilveira/circuit-engine/graphid"
 	"github.com/kssilveira/circuit-engine/sfmt"
 	"github.com/kssilveira/circuit-engine/wire"
 )
@@ -54,30 +55,33 @@ func (t Transistor) String(depth int, _ config.Config) string {
 }
 
 // Graph returns the graphviz graph.
-func (t Transistor) Graph(depth int, cfg config.Config) string {
+func (t *Transistor) Graph(depth int, cfg config.Config, ids *graphid.IDs) string {
 	if !cfg.DrawNodes {
 		return ""
 	}
 	prefix := draw.GraphPrefix(depth)
+	id, _ := ids.ID("t", t)
 	var res []string
-	res = append(res, sfmt.Sprintf(`"%p" [label="𓇲";shape=invtriangle];`, &t))
+	res = append(res, sfmt.Sprintf(`%s"%s" [label="𓇲";shape=invtriangle];`, prefix, id))
 	for _, wire := range []*wire.Wire{t.Base, t.Collector} {
-		if cfg.DrawShapePoint {
-			res = append(res, sfmt.Sprintf(`%s"%v" [label= "";shape=point];`, prefix, *wire))
+		wireID, node := draw.WireNode(ids, wire, cfg.DrawShapePoint)
+		if node != "" {
+			res = append(res, prefix+node)
 		}
 		if cfg.DrawEdges {
-			res = append(res, sfmt.Sprintf(`%s"%v" -> "%p" %s;`, prefix, *wire, &t, draw.EdgeColor(wire, wire)))
+			res = append(res, sfmt.Sprintf(`%s"%s" -> "%s" %s;`, prefix, wireID, id, draw.EdgeColor(wire, wire)))
 		}
 	}
 	for _, wire := range []*wire.Wire{t.Emitter, t.CollectorOut} {
 		if wire.Name == "Unused" {
 			continue
 		}
-		if cfg.DrawShapePoint {
-			res = append(res, sfmt.Sprintf(`%s"%v" [label= "";shape=point];`, prefix, *wire))
+		wireID, node := draw.WireNode(ids, wire, cfg.DrawShapePoint)
+		if node != "" {
+			res = append(res, prefix+node)
 		}
 		if cfg.DrawEdges {
-			res = append(res, sfmt.Sprintf(`%s"%p" -> "%v" %s;`, prefix, &t, *wire, draw.EdgeColor(wire, wire)))
+			res = append(res, sfmt.Sprintf(`%s"%s" -> "%s" %s;`, prefix, id, wireID, draw.EdgeColor(wire, wire)))
 		}
 	}
 	return strings.Join(res, "\n")
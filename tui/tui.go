@@ -0,0 +1,90 @@
+// Package tui toggles the inputs of a circuit from the terminal and shows the values live.
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kssilveira/circuit-engine/session"
+	"github.com/kssilveira/circuit-engine/wire"
+)
+
+const (
+	// Keys contains the key of each input, in order, skipping "q".
+	Keys = "1234567890abcdefghijklmnoprstuvwxyz"
+	// MaxChanges is the number of changed nets shown.
+	MaxChanges  = 20
+	clearScreen = "\x1b[H\x1b[2J"
+	ctrlC       = 3
+)
+
+// Run shows the session and toggles an input and updates the circuit on each key, until "q".
+//
+// The input should be a terminal in raw mode, so each key is read as soon as it is pressed.
+func Run(s *session.Session, in io.Reader, out io.Writer) error {
+	reader := bufio.NewReader(in)
+	var changes []session.Change
+	var err error
+	for {
+		if _, err := io.WriteString(out, Screen(s, changes, err)); err != nil {
+			return err
+		}
+		key, readErr := reader.ReadByte()
+		if readErr == io.EOF || key == 'q' || key == ctrlC {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+		index := strings.IndexByte(Keys, key)
+		if index < 0 || index >= len(s.Circuit.Inputs) {
+			changes, err = nil, fmt.Errorf("key %q is not an input", key)
+			continue
+		}
+		changes, err = s.Toggle(index)
+	}
+}
+
+// Screen returns the screen with the inputs, outputs, probes and the changes of the last update.
+func Screen(s *session.Session, changes []session.Change, err error) string {
+	res := []string{
+		clearScreen + fmt.Sprintf("step %d, press a key to toggle an input and update, q to quit", s.Steps),
+		"",
+		"Inputs:",
+	}
+	for i, input := range s.Circuit.Inputs {
+		key := "-"
+		if i < len(Keys) {
+			key = Keys[i : i+1]
+		}
+		res = append(res, fmt.Sprintf("  [%s] %v", key, *input))
+	}
+	res = append(res, values("Outputs:", s.Circuit.Outputs)...)
+	res = append(res, values("Probes:", s.Probes)...)
+	res = append(res, "", fmt.Sprintf("Changed: %d", len(changes)))
+	for i, change := range changes {
+		if i >= MaxChanges {
+			res = append(res, fmt.Sprintf("  ... and %d more", len(changes)-MaxChanges))
+			break
+		}
+		res = append(res, "  "+change.String())
+	}
+	if err != nil {
+		res = append(res, "", fmt.Sprintf("error: %v", err))
+	}
+	// raw terminals do not return the carriage on new lines
+	return strings.Join(res, "\r\n") + "\r\n"
+}
+
+func values(title string, wires []*wire.Wire) []string {
+	if len(wires) == 0 {
+		return nil
+	}
+	res := []string{"", title}
+	for _, w := range wires {
+		res = append(res, fmt.Sprintf("  %v", *w))
+	}
+	return res
+}
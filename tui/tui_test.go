@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kssilveira/circuit-engine/circuit"
+	"github.com/kssilveira/circuit-engine/config"
+	"github.com/kssilveira/circuit-engine/lib"
+	"github.com/kssilveira/circuit-engine/session"
+)
+
+func TestRun(t *testing.T) {
+	c := circuit.NewCircuit(config.Config{})
+	c.Outs(lib.Example(c, "HalfSum"))
+	s := session.New(c)
+	if _, err := s.Watch("*-wire"); err != nil {
+		t.Fatalf("Watch got err %v", err)
+	}
+	var out strings.Builder
+	if err := Run(s, strings.NewReader("1x2q"), &out); err != nil {
+		t.Fatalf("Run got err %v", err)
+	}
+	screens := strings.Split(out.String(), clearScreen)[1:]
+	if len(screens) != 4 {
+		t.Fatalf("Run want 4 screens got %d", len(screens))
+	}
+	for i, want := range []string{
+		"[1] a=0\r\n  [2] b=0\r\n\r\nOutputs:\r\n  S(a,b)=0\r\n  C(a,b)=0\r\n\r\nProbes:\r\n  NAND(a,b)-wire=0",
+		"[1] a=1\r\n  [2] b=0\r\n\r\nOutputs:\r\n  S(a,b)=1\r\n  C(a,b)=0",
+		`error: key 'x' is not an input`,
+		"Outputs:\r\n  S(a,b)=0\r\n  C(a,b)=1",
+	} {
+		if !strings.Contains(screens[i], want) {
+			t.Errorf("screen %d want to contain %q got\n%s", i, want, screens[i])
+		}
+	}
+	if !strings.Contains(screens[1], "a=1\r\n  S(a,b)=1") {
+		t.Errorf("screen 1 want the changed nets got\n%s", screens[1])
+	}
+}
+
+func TestRunInvalid(t *testing.T) {
+	c := circuit.NewCircuit(config.Config{})
+	c.Outs(lib.Example(c, "HalfSum"))
+	c.AddInputValidation(func() bool { return !c.Inputs[0].Bit.SilentGet() })
+	s := session.New(c)
+	var out strings.Builder
+	if err := Run(s, strings.NewReader("12q"), &out); err != nil {
+		t.Fatalf("Run got err %v", err)
+	}
+	screens := strings.Split(out.String(), clearScreen)[1:]
+	// the toggle that fails validation is reverted
+	for i, want := range []string{
+		"error: inputs 10 fail validation",
+		"[1] a=0\r\n  [2] b=1\r\n",
+	} {
+		if !strings.Contains(screens[i+1], want) {
+			t.Errorf("screen %d want to contain %q got\n%s", i+1, want, screens[i+1])
+		}
+	}
+	if want, got := "01", s.Inputs(); want != got {
+		t.Errorf("Inputs want %q got %q", want, got)
+	}
+}